@@ -0,0 +1,76 @@
+package conf
+
+// SetAlias registers alias as an alternative name of the canonical key, so that reading and
+// writing the alias (eg. with StringK or SetRawK) operates on the canonical key instead. Only a
+// single level of aliases is followed: an alias of an alias is not resolved further. Lines that set
+// the alias itself are not affected. Passing an empty canonical key removes the alias.
+// Useful for treating deprecated parameter names transparently.
+func (c *Conf) SetAlias(alias, canonical string) {
+	if canonical == "" {
+		delete(c.aliases, NormalizeKey(alias))
+		return
+	}
+	if c.aliases == nil {
+		c.aliases = make(map[string]string)
+	}
+	c.aliases[NormalizeKey(alias)] = canonical
+}
+
+// resolveAlias returns the canonical key of an alias registered with SetAlias, or the key itself
+// if it is not an alias.
+func (c *Conf) resolveAlias(key string) string {
+	if canonical, ok := c.aliases[NormalizeKey(key)]; ok {
+		return canonical
+	}
+	return key
+}
+
+// resolveAliasK resolves the alias of a key at the place where methods that read the
+// configuration use it (eg. LookupKey or EffectiveLineK), so that readers, which pass the key on
+// unchanged, follow a single level of aliases. Within a mutator the key is returned unchanged, as
+// it was already resolved by aliasScope. Readers never modify the configuration, which keeps
+// them safe for concurrent use (see WithReadLock).
+func (c *Conf) resolveAliasK(key string) string {
+	if c.aliasing {
+		return key
+	}
+	return c.resolveAlias(key)
+}
+
+// aliasScope resolves the aliases of the keys passed to a mutator, and returns a function that the
+// mutator must defer. Methods called before the returned function is called get their keys
+// unchanged, as they were already resolved, so that only a single level of aliases is followed
+// (eg. SetIntK calls LookupOrAppendK with the resolved key).
+func (c *Conf) aliasScope(keys ...*string) func() {
+	if c.aliasing {
+		return func() {}
+	}
+	c.aliasing = true
+	for _, key := range keys {
+		*key = c.resolveAlias(*key)
+	}
+	return func() { c.aliasing = false }
+}
+
+// aliasScopeSlice is aliasScope for methods that take a slice of keys. The keys are resolved in a
+// copy of the slice, which is returned.
+func (c *Conf) aliasScopeSlice(keys []string) ([]string, func()) {
+	resolved := append([]string(nil), keys...)
+	pointers := make([]*string, len(resolved))
+	for i := range resolved {
+		pointers[i] = &resolved[i]
+	}
+	return resolved, c.aliasScope(pointers...)
+}
+
+// cloneAliases returns a copy of the registered aliases.
+func (c *Conf) cloneAliases() map[string]string {
+	if c.aliases == nil {
+		return nil
+	}
+	aliases := make(map[string]string, len(c.aliases))
+	for alias, canonical := range c.aliases {
+		aliases[alias] = canonical
+	}
+	return aliases
+}
@@ -0,0 +1,80 @@
+package conf_test
+
+import (
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestSetAlias(t *testing.T) {
+	c := conf.New("port = 5432\nold_name = 1\n")
+	c.SetAlias("old_name", "port")
+	c.SetAlias("older_name", "old_name")
+
+	if got, err := c.IntK("OLD_NAME"); err != nil || got != 5432 {
+		t.Errorf("IntK(%q) = %d, %v, want %d", "OLD_NAME", got, err, 5432)
+	}
+	if got, err := c.IntK("older_name"); err != nil || got != 1 {
+		t.Errorf("IntK(%q) = %d, %v, want %d from a single level of aliases", "older_name", got, err, 1)
+	}
+
+	if err := c.SetIntK("old_name", 6000); err != nil {
+		t.Fatalf("SetIntK(%q) errored with '%s', wanted no error", "old_name", err)
+	}
+	if err := c.SetRawK("new_alias", "on"); err != nil {
+		t.Fatalf("SetRawK(%q) errored with '%s', wanted no error", "new_alias", err)
+	}
+	c.SetAlias("wal_alias", "fsync")
+	if err := c.SetRawK("wal_alias", "off"); err != nil {
+		t.Fatalf("SetRawK(%q) errored with '%s', wanted no error", "wal_alias", err)
+	}
+	want := "port = 6000\nold_name = 1\nnew_alias = on\nfsync = off"
+	if got := c.All(); got != want {
+		t.Errorf("setters with aliases changed configuration to %q, want %q", got, want)
+	}
+
+	clone := c.Clone()
+	c.SetAlias("old_name", "")
+	if got, _ := c.IntK("old_name"); got != 1 {
+		t.Errorf("IntK(%q) after removing alias = %d, want %d", "old_name", got, 1)
+	}
+	if got, _ := clone.IntK("old_name"); got != 6000 {
+		t.Errorf("IntK(%q) of clone = %d, want %d", "old_name", got, 6000)
+	}
+}
+
+func TestSetAlias_Methods(t *testing.T) {
+	c := conf.New("shared_buffers = 128MB\nmin_wal_size = 80MB\nport = 5432\n")
+	c.SetAlias("sb", "shared_buffers")
+	c.SetAlias("mws", "max_wal_size")
+	c.SetAlias("mc", "max_connections")
+
+	if got, err := c.AsBytesK("sb"); err != nil || got != 128*1024*1024 {
+		t.Errorf("AsBytesK(%q) = %d, %v, want %d", "sb", got, err, 128*1024*1024)
+	}
+	if got, err := c.EffectiveLineK("SB"); err != nil || got != 1 {
+		t.Errorf("EffectiveLineK(%q) = %d, %v, want %d", "SB", got, err, 1)
+	}
+	if got, err := c.GetMany("sb", "min_wal_size"); err != nil || got["sb"] != "128MB" || got["min_wal_size"] != "80MB" {
+		t.Errorf("GetMany() = %q, %v, want values for sb and min_wal_size", got, err)
+	}
+	if err := c.SetRawKMaxLen("sb", "256MB", 30); err != nil {
+		t.Errorf("SetRawKMaxLen(%q) errored with '%s', wanted no error", "sb", err)
+	}
+
+	if err := c.SetRawAfterK("min_wal_size", "mws", "1GB"); err != nil {
+		t.Fatalf("SetRawAfterK(%q) errored with '%s', wanted no error", "mws", err)
+	}
+	want := "shared_buffers = 256MB\nmin_wal_size = 80MB\nmax_wal_size = 1GB\nport = 5432\n"
+	if got := c.All(); got != want {
+		t.Errorf("SetRawAfterK(%q) changed configuration to %q, want %q", "mws", got, want)
+	}
+
+	c.SetStrictValidation(true)
+	if err := c.SetIntK("mc", 0); err == nil {
+		t.Errorf("SetIntK(%q, 0) did not error, wanted error from validation of max_connections", "mc")
+	}
+	if got := c.All(); got != want {
+		t.Errorf("SetIntK(%q, 0) changed configuration to %q, want %q", "mc", got, want)
+	}
+}
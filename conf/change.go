@@ -0,0 +1,180 @@
+package conf
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ChangeKind describes how a setting changed.
+type ChangeKind int
+
+// Constants for kinds of changes
+const (
+	Added ChangeKind = iota
+	Modified
+	Removed
+)
+
+// String returns a human readable name of the change kind.
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Modified:
+		return "modified"
+	case Removed:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// Change describes a difference in the effective value of a setting.
+type Change struct {
+	Key  string     // Name of the setting
+	Kind ChangeKind // Whether the setting was added, modified or removed
+	Old  string     // Dequoted old value, empty if the setting was added
+	New  string     // Dequoted new value, empty if the setting was removed
+}
+
+// effectiveValues returns the dequoted effective values keyed by lowercased key names.
+func (c *Conf) effectiveValues() map[string]string {
+	m := make(map[string]string)
+	for _, p := range c.OrderedPairs() {
+		m[NormalizeKey(p[0])] = p[1]
+	}
+	return m
+}
+
+// Equal tests if two configurations have the same effective settings, ignoring comments,
+// whitespace, the order of settings, the case of keys and the quoting of values.
+func Equal(a, b *Conf) bool {
+	return reflect.DeepEqual(a.effectiveValues(), b.effectiveValues())
+}
+
+// plainValuePattern matches values that are written unquoted by Canonical.
+var plainValuePattern = regexp.MustCompile(`^[A-Za-z0-9_.+-]+$`)
+
+// Canonical returns the effective settings as "key = value" lines sorted by key, with keys in
+// lowercase and values in a normalized form: simple values (eg. numbers, units and booleans) are
+// written unquoted, while all other values are enclosed in single quotes. Comments, whitespace
+// and the order of settings are ignored, so configurations that are Equal have the same canonical
+// form. Useful for comparison with golden files in tests.
+func (c *Conf) Canonical() string {
+	values := c.effectiveValues()
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		value := values[key]
+		if !plainValuePattern.MatchString(value) {
+			value = c.Quote(value)
+		}
+		b.WriteString(key + " = " + value + "\n")
+	}
+	return b.String()
+}
+
+// Plan compares the effective settings with the desired values and returns the changes that
+// FromMap(desired) would make, sorted by key. Keys that already have the desired value are
+// excluded. The configuration is not modified.
+func (c *Conf) Plan(desired map[string]string) []Change {
+	current := c.effectiveValues()
+	var changes []Change
+	for key, value := range desired {
+		old, ok := current[NormalizeKey(key)]
+		if !ok {
+			changes = append(changes, Change{Key: key, Kind: Added, New: value})
+		} else if old != value {
+			changes = append(changes, Change{Key: key, Kind: Modified, Old: old, New: value})
+		}
+	}
+	sortChanges(changes)
+	return changes
+}
+
+// Snapshot returns a copy of the configuration, that can later be passed to ChangedSince.
+// It is an alias of Clone.
+func (c *Conf) Snapshot() *Conf {
+	return c.Clone()
+}
+
+// ChangedSince compares the effective settings with the ones in the snapshot and returns the
+// settings that were added, modified or removed since the snapshot was taken, sorted by key.
+// Keys are reported in lowercase.
+func (c *Conf) ChangedSince(snap *Conf) []Change {
+	old := snap.effectiveValues()
+	current := c.effectiveValues()
+	var changes []Change
+	for key, value := range current {
+		oldValue, ok := old[key]
+		if !ok {
+			changes = append(changes, Change{Key: key, Kind: Added, New: value})
+		} else if oldValue != value {
+			changes = append(changes, Change{Key: key, Kind: Modified, Old: oldValue, New: value})
+		}
+	}
+	for key, oldValue := range old {
+		if _, ok := current[key]; !ok {
+			changes = append(changes, Change{Key: key, Kind: Removed, Old: oldValue})
+		}
+	}
+	sortChanges(changes)
+	return changes
+}
+
+// OverrideOf returns a new configuration with only the settings whose effective values differ from
+// the ones in base, including settings that are not set in base, in the order they appear in c.
+// Values are copied raw, as written in c. The result is suitable for a drop-in file read after
+// base (eg. with include_dir), that turns the effective settings of base into the ones of c.
+// Settings that are set in base, but not in c, cannot be expressed as overrides and are ignored.
+func (c *Conf) OverrideOf(base *Conf) *Conf {
+	changed := make(map[string]bool)
+	for _, ch := range c.ChangedSince(base) {
+		if ch.Kind != Removed {
+			changed[ch.Key] = true
+		}
+	}
+
+	override := New("")
+	for _, s := range c.effectiveSettings() {
+		if !changed[NormalizeKey(s.key)] {
+			continue
+		}
+		value, err := c.Raw(s.row, valueCol)
+		if err != nil {
+			continue
+		}
+		override.Append(s.key, value)
+	}
+	return override
+}
+
+// RestartRequired returns the keys of changed settings, that take effect only after a server
+// restart (ie. have ContextPostmaster in the GUC registry), in the order of the changes. Changes
+// to all other settings are applied by reloading the configuration. Settings unknown to the
+// registry are not reported.
+func (c *Conf) RestartRequired(changes []Change) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, ch := range changes {
+		g, ok := LookupGUC(ch.Key)
+		if !ok || g.Context != ContextPostmaster || seen[g.Name] {
+			continue
+		}
+		seen[g.Name] = true
+		keys = append(keys, ch.Key)
+	}
+	return keys
+}
+
+func sortChanges(changes []Change) {
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+}
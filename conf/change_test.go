@@ -0,0 +1,155 @@
+package conf_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestPlan(t *testing.T) {
+	content := "port = 5432\nshared_buffers = '128MB'\nwork_mem = 4MB\n"
+	c := conf.New(content)
+
+	got := c.Plan(map[string]string{
+		"port":           "5432",  // Already matching
+		"shared_buffers": "256MB", // Different
+		"work_mem":       "4MB",   // Already matching, but unquoted in file
+		"wal_level":      "logical",
+	})
+	want := []conf.Change{
+		{Key: "shared_buffers", Kind: conf.Modified, Old: "128MB", New: "256MB"},
+		{Key: "wal_level", Kind: conf.Added, Old: "", New: "logical"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Plan() = %+v, want %+v", got, want)
+	}
+	if c.All() != content {
+		t.Errorf("Plan() modified the configuration to %q", c.All())
+	}
+}
+
+func TestChangedSince(t *testing.T) {
+	c := conf.New("port = 5432\nshared_buffers = '128MB'\nwork_mem = 4MB\n")
+	snap := c.Snapshot()
+
+	if got := c.ChangedSince(snap); len(got) != 0 {
+		t.Errorf("ChangedSince() = %+v right after Snapshot(), want no changes", got)
+	}
+
+	c.SetRawK("shared_buffers", "'256MB'")
+	c.SetIntK("max_connections", 200)
+
+	got := c.ChangedSince(snap)
+	want := []conf.Change{
+		{Key: "max_connections", Kind: conf.Added, Old: "", New: "200"},
+		{Key: "shared_buffers", Kind: conf.Modified, Old: "128MB", New: "256MB"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedSince() = %+v, want %+v", got, want)
+	}
+
+	if _, err := c.KeepOnly("shared_buffers", "max_connections"); err != nil {
+		t.Fatalf("KeepOnly() errored with '%s', wanted no error", err)
+	}
+	got = c.ChangedSince(snap)
+	want = []conf.Change{
+		{Key: "max_connections", Kind: conf.Added, Old: "", New: "200"},
+		{Key: "port", Kind: conf.Removed, Old: "5432", New: ""},
+		{Key: "shared_buffers", Kind: conf.Modified, Old: "128MB", New: "256MB"},
+		{Key: "work_mem", Kind: conf.Removed, Old: "4MB", New: ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedSince() after removals = %+v, want %+v", got, want)
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	c := conf.New("shared_buffers = 128MB\nwork_mem = 4MB\nmax_connections = 100\n")
+	changes := c.Plan(map[string]string{
+		"shared_buffers":  "1GB",
+		"work_mem":        "16MB",
+		"max_connections": "200",
+		"my_ext.setting":  "on",
+	})
+
+	got := c.RestartRequired(changes)
+	want := []string{"max_connections", "shared_buffers"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RestartRequired() = %q, want %q", got, want)
+	}
+
+	if got := c.RestartRequired(c.Plan(map[string]string{"work_mem": "16MB"})); got != nil {
+		t.Errorf("RestartRequired() = %q for a reloadable setting, want nil", got)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a := conf.New("# Connections\nport = 5432\nlisten_addresses = '*'\n\nwork_mem = 4MB\n")
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"Formatting only", "listen_addresses='*'   # all\nWORK_MEM  '4MB'\n\tport\t=\t5432\n", true},
+		{"Shadowed value", "port = 5433\nlisten_addresses = \"*\"\nwork_mem = 4MB\nport = 5432\n", true},
+		{"Changed value", "port = 5433\nlisten_addresses = '*'\nwork_mem = 4MB\n", false},
+		{"Missing key", "port = 5432\nlisten_addresses = '*'\n", false},
+		{"Extra key", "port = 5432\nlisten_addresses = '*'\nwork_mem = 4MB\nfsync = on\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := conf.New(tt.content)
+			if got := conf.Equal(a, b); got != tt.want {
+				t.Errorf("Equal() = %t, want %t", got, tt.want)
+			}
+			if got := conf.Equal(b, a); got != tt.want {
+				t.Errorf("Equal() with swapped arguments = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	a := conf.New("# Connections\nport = 5432\nlisten_addresses = 'localhost, 10.0.0.1'\n\nwork_mem = 4MB\nsearch_path = '\"$user\", public'\n")
+	b := conf.New("search_path=\"\\\"$user\\\", public\"\nWORK_MEM  '4MB'   # per operation\n\tport\t=\t5432\nlisten_addresses = 'localhost, 10.0.0.1'\n")
+	want := "listen_addresses = 'localhost, 10.0.0.1'\n" +
+		"port = 5432\n" +
+		"search_path = '\"$user\", public'\n" +
+		"work_mem = 4MB\n"
+
+	if got := a.Canonical(); got != want {
+		t.Errorf("Canonical() = %q, want %q", got, want)
+	}
+	if got := b.Canonical(); got != want {
+		t.Errorf("Canonical() of differently formatted configuration = %q, want %q", got, want)
+	}
+	if got := conf.New("").Canonical(); got != "" {
+		t.Errorf("Canonical() of empty configuration = %q, want %q", got, "")
+	}
+}
+
+func TestOverrideOf(t *testing.T) {
+	base := conf.New("port = 5432\nwork_mem = 4MB\nlisten_addresses = 'localhost'\nfsync = on\n")
+	c := conf.New("# Tuned\nWORK_MEM = 64MB\nport = '5432'   # same value, quoted\nlog_line_prefix = %m [%p] \nlisten_addresses = 'localhost'\n")
+	want := "WORK_MEM = 64MB\nlog_line_prefix = %m [%p]"
+
+	override := c.OverrideOf(base)
+	if got := override.All(); got != want {
+		t.Errorf("OverrideOf() = %q, want %q", got, want)
+	}
+
+	// Base with the override applied has the effective settings of c, except for removed keys
+	merged := conf.New(base.All() + override.All())
+	for _, key := range []string{"port", "work_mem", "log_line_prefix", "listen_addresses"} {
+		got, _ := merged.StringK(key)
+		want, _ := c.StringK(key)
+		if got != want {
+			t.Errorf("StringK(%q) of base with override = %q, want %q", key, got, want)
+		}
+	}
+
+	if got := base.OverrideOf(base.Clone()).All(); got != "" {
+		t.Errorf("OverrideOf() of identical configuration = %q, want %q", got, "")
+	}
+}
@@ -0,0 +1,80 @@
+package conf
+
+import (
+	"fmt"
+	"hash/crc32"
+	"io"
+	"time"
+)
+
+// SetChangeLog sets the writer, to which every successful change made by the mutators of this
+// package (eg. SetRawK, SetStringK, DeleteK or AppendFormatted) is logged as a single line with
+// the time (in UTC), the name of the method, the key and the old and new raw values
+// (eg. "2018-01-01T10:00:00Z SetIntK port: 5432 -> 6000"). Keys that were not set are logged
+// with the value (unset). SetCommentK logs the old and new inline comment instead of the value.
+// Mutators that change the whole file (eg. KeepOnly, MapValues or TidyBlankLines) log the
+// number of lines and the CRC-32 checksum of the content in place of a key and values. Changes
+// made through a mutator that calls another mutator are logged once, with the name of the method
+// called. Errors returned by the writer are ignored. Passing nil disables logging. The writer is
+// not copied by Clone.
+func (c *Conf) SetChangeLog(w io.Writer) {
+	c.changeLog = w
+}
+
+// unsetValue is logged in place of the value of keys that are not set.
+const unsetValue = "(unset)"
+
+// rawOrUnset retrieves the raw value of the key, or unsetValue if the key is not set.
+func (c *Conf) rawOrUnset(key string) string {
+	value, err := c.RawK(key)
+	if err != nil {
+		return unsetValue
+	}
+	return value
+}
+
+// contentSummary describes the whole content for the change log, as the number of lines and the
+// CRC-32 checksum of the content.
+func (c *Conf) contentSummary() string {
+	return fmt.Sprintf("%d lines, crc32 %08x", len(c.Lines()), crc32.ChecksumIEEE([]byte(c.All())))
+}
+
+// logChange records the state of the subject of a change, as returned by state, and returns a
+// function that logs the change if the error it is given points to nil. Every mutator defers the
+// returned function with its named error result:
+//
+//	defer c.logChange("SetRawK", key, c.rawOrUnset)(&err)
+//
+// Changes made by mutators called from another mutator are not logged.
+func (c *Conf) logChange(method, subject string, state func(subject string) string) func(err *error) {
+	if c.changeLog == nil || c.logging {
+		return func(*error) {}
+	}
+	c.logging = true
+	old := state(subject)
+	return func(err *error) {
+		c.logging = false
+		if *err == nil {
+			c.writeChange(method, subject, old, state(subject))
+		}
+	}
+}
+
+// logChangeK is logChange for mutators that change the value of a single key.
+func (c *Conf) logChangeK(method, key string) func(err *error) {
+	return c.logChange(method, key, c.rawOrUnset)
+}
+
+// logChangeAll is logChange for mutators that change the whole file.
+func (c *Conf) logChangeAll(method string) func(err *error) {
+	return c.logChange(method, "", func(string) string { return c.contentSummary() })
+}
+
+// writeChange writes a line describing the change to the change log. The subject is omitted if
+// it is empty.
+func (c *Conf) writeChange(method, subject, old, new string) {
+	if subject != "" {
+		method += " " + subject
+	}
+	fmt.Fprintf(c.changeLog, "%s %s: %s -> %s\n", time.Now().UTC().Format(time.RFC3339), method, old, new)
+}
@@ -0,0 +1,64 @@
+package conf_test
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestSetChangeLog(t *testing.T) {
+	var log bytes.Buffer
+	c := conf.New("port = 5432\nfsync = on\n")
+	c.SetChangeLog(&log)
+
+	c.SetIntK("port", 6000)
+	c.SetOnOffK("fsync", false)
+	c.SetStringK("work_mem", "64MB")
+	c.SetRawAtLineK(1, "6001")
+	c.SetCommentK("port", "moved off default")
+	c.EnableK("work_mem", "'128MB'")
+	if err := c.SetTimezoneK("timezone", "Not/AZone"); err == nil {
+		t.Errorf("SetTimezoneK() did not error, wanted error")
+	}
+
+	timestamp := regexp.MustCompile(`^\d{4}-\d\d-\d\dT\d\d:\d\d:\d\dZ `)
+	var got []string
+	for _, line := range strings.Split(strings.TrimSuffix(log.String(), "\n"), "\n") {
+		if !timestamp.MatchString(line) {
+			t.Errorf("SetChangeLog() logged %q, want a line starting with a timestamp", line)
+		}
+		got = append(got, timestamp.ReplaceAllString(line, ""))
+	}
+	want := []string{
+		"SetIntK port: 5432 -> 6000",
+		"SetOnOffK fsync: on -> off",
+		"SetStringK work_mem: (unset) -> '64MB'",
+		"SetRawAtLineK port: 6000 -> 6001",
+		"SetCommentK port: (none) -> moved off default",
+		"EnableK work_mem: '64MB' -> '128MB'",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("SetChangeLog() logged %q, want %q", got, want)
+	}
+
+	log.Reset()
+	if _, err := c.KeepOnly("port"); err != nil {
+		t.Fatalf("KeepOnly() errored with '%s', wanted no error", err)
+	}
+	c.TidyBlankLines()
+	whole := regexp.MustCompile(`^KeepOnly: 3 lines, crc32 [0-9a-f]{8} -> 1 lines, crc32 [0-9a-f]{8}\n` +
+		`\S+ TidyBlankLines: 1 lines, crc32 ([0-9a-f]{8}) -> 1 lines, crc32 ([0-9a-f]{8})\n$`)
+	if !whole.MatchString(timestamp.ReplaceAllString(log.String(), "")) {
+		t.Errorf("SetChangeLog() logged %q for changes to the whole file, want a line for KeepOnly and TidyBlankLines", log.String())
+	}
+
+	log.Reset()
+	c.SetChangeLog(nil)
+	c.SetIntK("port", 7000)
+	if log.Len() != 0 {
+		t.Errorf("SetChangeLog(nil) did not disable logging, got %q", log.String())
+	}
+}
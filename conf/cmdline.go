@@ -0,0 +1,52 @@
+package conf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ApplyCommandLine applies overrides given in the style of PostgreSQL's command line options,
+// ie. "-c name=value" (as two arguments or as "-cname=value") and "--name=value". Dashes in names
+// are converted to underscores, as PostgreSQL does. Other arguments are ignored.
+// Values are written as raw values, except for values containing whitespace, quotes or comment
+// characters, which are quoted, so that they are read back unchanged (PostgreSQL does not dequote
+// values given on the command line).
+// All arguments are parsed before any value is applied, so that an error for a malformed option
+// leaves the configuration unchanged. Returns the number of overrides applied.
+func (c *Conf) ApplyCommandLine(args []string) (int, error) {
+	var overrides [][2]string
+	for i := 0; i < len(args); i++ {
+		var option string
+		switch arg := args[i]; {
+		case arg == "-c":
+			if i+1 == len(args) {
+				return 0, fmt.Errorf("option -c requires an argument")
+			}
+			i++
+			option = args[i]
+		case strings.HasPrefix(arg, "--"):
+			option = arg[2:]
+		case strings.HasPrefix(arg, "-c"):
+			option = arg[2:]
+		default:
+			continue
+		}
+
+		eq := strings.IndexRune(option, '=')
+		if eq == -1 {
+			return 0, fmt.Errorf("option %q must be in the form name=value", option)
+		}
+		key := strings.Replace(strings.TrimSpace(option[:eq]), "-", "_", -1)
+		if !keyPattern.MatchString(key) {
+			return 0, fmt.Errorf("invalid parameter name %q in option %q", key, option)
+		}
+		overrides = append(overrides, [2]string{key, option[eq+1:]})
+	}
+
+	for n, o := range overrides {
+		if err := c.SetRawK(o[0], c.quoteIfNeeded(o[1])); err != nil {
+			return n, fmt.Errorf("could not set %s: %s", o[0], err)
+		}
+	}
+	return len(overrides), nil
+}
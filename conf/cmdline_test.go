@@ -0,0 +1,48 @@
+package conf_test
+
+import (
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestApplyCommandLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		want      string
+		wantCount int
+		wantErr   bool
+	}{
+		{
+			"Separate arguments",
+			[]string{"-c", "port=6000", "-c", "fsync=off"},
+			"port = 6000\nfsync = off\n", 2, false,
+		},
+		{
+			"Joined and long options",
+			[]string{"-D", "/var/lib/pgsql/data", "-cport=6000", "--shared-buffers=1GB", "--log_line_prefix=%m [%p] "},
+			"port = 6000\nfsync = on\nshared_buffers = 1GB\nlog_line_prefix = '%m [%p] '", 3, false,
+		},
+		{"Missing equal sign", []string{"-c", "port=6000", "-c", "fsync"}, "port = 5432\nfsync = on\n", 0, true},
+		{"Missing argument", []string{"-c", "port=6000", "-c"}, "port = 5432\nfsync = on\n", 0, true},
+		{"Invalid name", []string{"-c", "=6000"}, "port = 5432\nfsync = on\n", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New("port = 5432\nfsync = on\n")
+			count, err := c.ApplyCommandLine(tt.args)
+			if tt.wantErr && err == nil {
+				t.Errorf("ApplyCommandLine(%q) did not error, wanted error", tt.args)
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("ApplyCommandLine(%q) errored with '%s', wanted no error", tt.args, err)
+			}
+			if count != tt.wantCount {
+				t.Errorf("ApplyCommandLine(%q) = %d, want %d", tt.args, count, tt.wantCount)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("ApplyCommandLine(%q) changed configuration to %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
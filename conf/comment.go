@@ -0,0 +1,250 @@
+package conf
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/quasoft/pgconf/generic"
+)
+
+// keyPattern matches valid parameter names, including custom (namespaced) parameters.
+var keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// commentedSettingRow returns the row of a commented out setting (eg. #port = 5432).
+// The second return value is false for comment lines that do not look like a disabled setting
+// (eg. prose comments). Following the style of the sample postgresql.conf, a line is considered
+// a commented out setting only if the key follows the comment character immediately or after a
+// single space, and is separated from the value with an equal sign.
+func (c *Conf) commentedSettingRow(line generic.Line) (*generic.Row, bool) {
+	row, err := c.CommentedRowOf(line)
+	if err != nil {
+		return nil, false
+	}
+	row = c.joinUnquotedValue(row)
+	if row.ColCount() != 2 {
+		return nil, false
+	}
+	keyToken, _ := row.Token(keyCol)
+	valueToken, _ := row.Token(valueCol)
+	all := c.All()
+	before := strings.TrimSuffix(all[line.Start:keyToken.Start], " ")
+	if !strings.HasSuffix(before, string(c.Params().InlineComment)) || !keyPattern.MatchString(all[keyToken.Start:keyToken.End]) {
+		return nil, false
+	}
+	if !strings.Contains(all[keyToken.End:valueToken.Start], "=") {
+		return nil, false
+	}
+	return row, true
+}
+
+// CommentedKeys returns the names of settings that are commented out (eg. #port = 5432), in the
+// order they appear in the file. Comments that do not look like a disabled setting are ignored.
+func (c *Conf) CommentedKeys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, line := range c.Lines() {
+		row, ok := c.commentedSettingRow(line)
+		if !ok {
+			continue
+		}
+		key, err := c.Raw(row, keyCol)
+		if err != nil || seen[NormalizeKey(key)] {
+			continue
+		}
+		seen[NormalizeKey(key)] = true
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// lookupCommentedK returns the first line that contains the given key commented out.
+func (c *Conf) lookupCommentedK(key string) (generic.Line, error) {
+	for _, line := range c.Lines() {
+		row, ok := c.commentedSettingRow(line)
+		if !ok {
+			continue
+		}
+		rowKey, err := c.Raw(row, keyCol)
+		if err == nil && NormalizeKey(rowKey) == NormalizeKey(key) {
+			return line, nil
+		}
+	}
+	return generic.Line{}, generic.ErrKeyNotFound
+}
+
+// EnableK makes sure the key is active with the given raw value (including any quotes).
+// If the key is set, its value is replaced. If the key is only commented out (eg. #port = 5432),
+// the first such line is uncommented and its value replaced. Otherwise a new line is appended.
+// If the value cannot be set (eg. it is rejected by strict validation), the configuration is left
+// unchanged.
+func (c *Conf) EnableK(key string, value string) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("EnableK", key)(&err)
+	// Enable the key on a copy first, so that a rejected value does not leave the line uncommented
+	clone := c.Clone()
+	clone.aliases = nil // The key is already resolved
+	if err := clone.enableK(key, value); err != nil {
+		return err
+	}
+	return c.enableK(key, value)
+}
+
+// enableK implements EnableK for a key with resolved aliases.
+func (c *Conf) enableK(key string, value string) error {
+	if _, err := c.LookupKey(key); err == nil {
+		return c.SetRawK(key, value)
+	}
+
+	line, err := c.lookupCommentedK(key)
+	if err == nil {
+		if err := c.UncommentLine(line); err != nil {
+			return err
+		}
+	}
+	return c.SetRawK(key, value)
+}
+
+// CommentK comments out the line that sets the effective value of the key (see EffectiveLineK),
+// by inserting the comment character after any indentation (eg. #port = 5432), so that the old
+// value remains visible. An inline comment later on the line is kept.
+// Returns generic.ErrKeyNotFound if the key is not set.
+func (c *Conf) CommentK(key string) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("CommentK", key)(&err)
+	number, err := c.EffectiveLineK(key)
+	if err != nil {
+		return err
+	}
+	line, err := c.LineAt(number)
+	if err != nil {
+		return err
+	}
+	return c.CommentOutLine(line)
+}
+
+// UncommentK activates the first line, on which the key is commented out (eg. #port = 5432), by
+// removing the comment character and a single space following it, if any.
+// Returns generic.ErrKeyNotFound if the key is not commented out on any line.
+func (c *Conf) UncommentK(key string) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("UncommentK", key)(&err)
+	line, err := c.lookupCommentedK(key)
+	if err != nil {
+		return err
+	}
+	return c.UncommentLine(line)
+}
+
+// SetCommentK sets the inline comment of the line holding the key (eg. to note why its value was
+// changed), replacing any existing comment, while keeping the value and the whitespace before
+// the comment. If the line has no comment, one is added after the value,
+// separated by a single space. An empty comment removes the existing comment.
+// Returns generic.ErrKeyNotFound if the key is not set.
+func (c *Conf) SetCommentK(key, comment string) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChange("SetCommentK", key, c.commentOrNone)(&err)
+	row, err := c.LookupKey(key)
+	if err != nil {
+		return err
+	}
+	return c.SetComment(row, comment)
+}
+
+// commentOrNone retrieves the inline comment of the line holding the key, or (none) if the key is
+// not set or its line has no comment.
+func (c *Conf) commentOrNone(key string) string {
+	row, err := c.LookupKey(key)
+	if err != nil {
+		return "(none)"
+	}
+	comment, ok := c.Comment(row)
+	if !ok {
+		return "(none)"
+	}
+	return comment
+}
+
+// LineKind describes what a line of the configuration contains.
+type LineKind int
+
+// Constants for kinds of lines
+const (
+	BlankLine            LineKind = iota // Line containing only whitespace
+	CommentLine                          // Comment-only line, that is not a commented out setting
+	CommentedSettingLine                 // Commented out setting (eg. #port = 5432)
+	SettingLine                          // Setting, optionally followed by a comment
+)
+
+// String returns a human readable name of the line kind.
+func (k LineKind) String() string {
+	switch k {
+	case BlankLine:
+		return "blank"
+	case CommentLine:
+		return "comment"
+	case CommentedSettingLine:
+		return "commented setting"
+	case SettingLine:
+		return "setting"
+	}
+	return "unknown"
+}
+
+// Line is a physical line of the configuration, along with the kind of its content.
+type Line struct {
+	generic.Line
+	Kind LineKind
+}
+
+// ClassifiedLines returns every line of the configuration along with its kind, in a single pass.
+// Comments are classified as commented out settings using the same rules as CommentedKeys.
+func (c *Conf) ClassifiedLines() []Line {
+	lines := c.Lines()
+	result := make([]Line, len(lines))
+	for i, line := range lines {
+		result[i].Line = line
+		if _, err := c.RowOf(line); err != generic.ErrEmptyLine {
+			result[i].Kind = SettingLine
+		} else if _, ok := c.commentedSettingRow(line); ok {
+			result[i].Kind = CommentedSettingLine
+		} else if strings.TrimSpace(line.Text) != "" {
+			result[i].Kind = CommentLine
+		} else {
+			result[i].Kind = BlankLine
+		}
+	}
+	return result
+}
+
+// AddSectionBanner inserts a section banner in the style of the sample postgresql.conf, ie. the
+// title between two comment lines of dashes, directly above the line that sets the effective value
+// of beforeKey (see EffectiveLineK). The banner is appended at the end if beforeKey is not set.
+// The comment lines start with the inline comment character from Params.
+func (c *Conf) AddSectionBanner(title string, beforeKey string) (err error) {
+	defer c.aliasScope(&beforeKey)()
+	defer c.logChangeAll("AddSectionBanner")(&err)
+	if strings.ContainsAny(title, "\r\n") {
+		return fmt.Errorf("section title %q must be a single line", title)
+	}
+	// Lines of dashes drawn above and below the title, as in the sample postgresql.conf
+	comment := string(c.Params().InlineComment)
+	rule := comment + strings.Repeat("-", 78)
+	banner := []string{rule, comment + " " + title, rule}
+
+	number, err := c.EffectiveLineK(beforeKey)
+	if err == generic.ErrKeyNotFound {
+		for _, line := range banner {
+			c.AppendRawLine(line)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	line, err := c.LineAt(number)
+	if err != nil {
+		return err
+	}
+	return c.InsertRawLines(line, banner...)
+}
@@ -0,0 +1,255 @@
+package conf_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+	"github.com/quasoft/pgconf/generic"
+)
+
+func TestCommentedKeys(t *testing.T) {
+	conf := openTestFile(t, "postgresql-default.conf")
+
+	got := conf.CommentedKeys()
+	want := []string{
+		"data_directory",
+		"port",
+		"superuser_reserved_connections",
+		"work_mem",
+		"maintenance_work_mem",
+		"wal_level",
+		"fsync",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CommentedKeys() = %q, want %q", got, want)
+	}
+}
+
+func TestEnableK(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"Commented", "#wal_level = minimal\t\t# comment\nport = 5432\n", "wal_level = logical\t\t# comment\nport = 5432\n"},
+		{"Commented with indentation", "\t#wal_level = minimal\n", "\twal_level = logical\n"},
+		{"Active", "wal_level = replica # comment\n", "wal_level = logical # comment\n"},
+		{"Active and commented", "#wal_level = minimal\nwal_level = replica\n", "#wal_level = minimal\nwal_level = logical\n"},
+		{"Absent", "port = 5432\n", "port = 5432\nwal_level = logical"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(tt.content)
+			err := c.EnableK("wal_level", "logical")
+			if err != nil {
+				t.Fatalf("EnableK() errored with '%s', wanted no error", err)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("EnableK() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnableK_Invalid(t *testing.T) {
+	c := conf.New("#port = 5432\n")
+	c.SetStrictValidation(true)
+	if err := c.EnableK("port", "70000"); err == nil {
+		t.Errorf("EnableK() with an out of range value did not error, wanted error")
+	}
+	if got := c.All(); got != "#port = 5432\n" {
+		t.Errorf("EnableK() changed configuration to %q, want %q", got, "#port = 5432\n")
+	}
+}
+
+func TestClassifiedLines(t *testing.T) {
+	c := conf.New(readTestFile(t, "postgresql-default.conf"))
+	lines := c.ClassifiedLines()
+	if len(lines) != 49 {
+		t.Fatalf("ClassifiedLines() returned %d lines, want %d", len(lines), 49)
+	}
+
+	want := map[int]conf.LineKind{
+		1:  conf.CommentLine,
+		7:  conf.CommentLine, // Example in prose, indented after the comment character
+		11: conf.BlankLine,
+		16: conf.CommentedSettingLine,
+		17: conf.CommentLine,
+		25: conf.SettingLine,
+		27: conf.CommentedSettingLine,
+		28: conf.SettingLine,
+		35: conf.CommentLine,
+		49: conf.SettingLine,
+	}
+	for number, kind := range want {
+		line := lines[number-1]
+		if line.Number != number {
+			t.Errorf("ClassifiedLines()[%d].Number = %d, want %d", number-1, line.Number, number)
+		}
+		if line.Kind != kind {
+			t.Errorf("ClassifiedLines() classified line %d (%q) as %s, want %s", number, line.Text, line.Kind, kind)
+		}
+	}
+}
+
+func TestAddSectionBanner(t *testing.T) {
+	rule := "#" + strings.Repeat("-", 78)
+	tests := []struct {
+		name      string
+		conf      string
+		title     string
+		beforeKey string
+		want      string
+	}{
+		{
+			"Before key",
+			"port = 5432\n\nshared_buffers = 128MB\nwork_mem = 4MB\n",
+			"RESOURCE USAGE", "shared_buffers",
+			"port = 5432\n\n" + rule + "\n# RESOURCE USAGE\n" + rule + "\nshared_buffers = 128MB\nwork_mem = 4MB\n",
+		},
+		{
+			"Before first line",
+			"port = 5432\n",
+			"CONNECTIONS", "PORT",
+			rule + "\n# CONNECTIONS\n" + rule + "\nport = 5432\n",
+		},
+		{
+			"Missing key",
+			"port = 5432",
+			"WRITE-AHEAD LOG", "wal_level",
+			"port = 5432\n" + rule + "\n# WRITE-AHEAD LOG\n" + rule,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(tt.conf)
+			if err := c.AddSectionBanner(tt.title, tt.beforeKey); err != nil {
+				t.Fatalf("AddSectionBanner() errored with '%s', wanted no error", err)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("AddSectionBanner() changed configuration to %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	c := conf.New("port = 5432\n")
+	params := c.Params()
+	params.InlineComment = ';'
+	c.SetParams(params)
+	if err := c.AddSectionBanner("CONNECTIONS", "port"); err != nil {
+		t.Fatalf("AddSectionBanner() errored with '%s', wanted no error", err)
+	}
+	semicolonRule := ";" + strings.Repeat("-", 78)
+	if got, want := c.All(), semicolonRule+"\n; CONNECTIONS\n"+semicolonRule+"\nport = 5432\n"; got != want {
+		t.Errorf("AddSectionBanner() with comment character ; changed configuration to %q, want %q", got, want)
+	}
+
+	if err := conf.New("").AddSectionBanner("TWO\nLINES", "port"); err == nil {
+		t.Errorf("AddSectionBanner() with multi-line title did not error, wanted error")
+	}
+}
+
+func TestCommentK(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"Simple", "port = 5432\nfsync = on\n", "port", "#port = 5432\nfsync = on\n", false},
+		{"Indented with comment", "\tport = 5432\t# (change requires restart)\n", "PORT", "\t#port = 5432\t# (change requires restart)\n", false},
+		{"Last effective occurrence", "port = 5432\nport = 5433", "port", "port = 5432\n#port = 5433", false},
+		{"Already commented", "#port = 5432\n", "port", "#port = 5432\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(tt.content)
+			err := c.CommentK(tt.key)
+			if tt.wantErr && err != generic.ErrKeyNotFound {
+				t.Errorf("CommentK(%q) errored with '%v', want '%s'", tt.key, err, generic.ErrKeyNotFound)
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("CommentK(%q) errored with '%s', wanted no error", tt.key, err)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("CommentK(%q) changed configuration to %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUncommentK(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"Simple", "#port = 5432\nfsync = on\n", "port", "port = 5432\nfsync = on\n", false},
+		{"Space after marker", "# port = 5432\n", "port", "port = 5432\n", false},
+		{"Two spaces after marker", "#  port = 5432\n", "port", "#  port = 5432\n", true},
+		{"Multi-word value", "#log_line_prefix = %m [%p] \t# prefix\n", "log_line_prefix", "log_line_prefix = %m [%p] \t# prefix\n", false},
+		{"Indented with comment", "\t#port = 5432\t# (change requires restart)\n", "port", "\tport = 5432\t# (change requires restart)\n", false},
+		{"First commented line", "#port = 5432\n#port = 5433\n", "port", "port = 5432\n#port = 5433\n", false},
+		{"Prose comment", "# the port is 5432\n", "port", "# the port is 5432\n", true},
+		{"Active only", "port = 5432\n", "port", "port = 5432\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(tt.content)
+			err := c.UncommentK(tt.key)
+			if tt.wantErr && err != generic.ErrKeyNotFound {
+				t.Errorf("UncommentK(%q) errored with '%v', want '%s'", tt.key, err, generic.ErrKeyNotFound)
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("UncommentK(%q) errored with '%s', wanted no error", tt.key, err)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("UncommentK(%q) changed configuration to %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	c := conf.New("\tport = 5432\t# comment\n")
+	c.CommentK("port")
+	c.UncommentK("port")
+	if got, want := c.All(), "\tport = 5432\t# comment\n"; got != want {
+		t.Errorf("UncommentK() after CommentK() changed configuration to %q, want %q", got, want)
+	}
+}
+
+func TestSetCommentK(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		comment string
+		want    string
+		wantErr bool
+	}{
+		{"Replace", "port = 5432\t\t# (change requires restart)\r\nfsync = on\r\n", "port", "moved off default", "port = 5432\t\t# moved off default\r\nfsync = on\r\n", false},
+		{"Add", "port = 5432\nfsync = on", "fsync", "needed for safety", "port = 5432\nfsync = on # needed for safety", false},
+		{"Quoted hash", "search_path = '\"#user\", public'\n", "search_path", "schemas", "search_path = '\"#user\", public' # schemas\n", false},
+		{"Unquoted value with spaces", "log_line_prefix = %m [%p] # old\n", "log_line_prefix", "new", "log_line_prefix = %m [%p] # new\n", false},
+		{"Remove", "port = 5432   # old\nfsync = on\n", "port", "", "port = 5432\nfsync = on\n", false},
+		{"Remove missing", "port = 5432\n", "port", "", "port = 5432\n", false},
+		{"Multi-line comment", "port = 5432\n", "port", "a\nb", "port = 5432\n", true},
+		{"Missing key", "port = 5432\n", "fsync", "x", "port = 5432\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(tt.content)
+			err := c.SetCommentK(tt.key, tt.comment)
+			if tt.wantErr && err == nil {
+				t.Errorf("SetCommentK(%q, %q) did not error, wanted error", tt.key, tt.comment)
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("SetCommentK(%q, %q) errored with '%s', wanted no error", tt.key, tt.comment, err)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("SetCommentK(%q, %q) changed configuration to %q, want %q", tt.key, tt.comment, got, tt.want)
+			}
+		})
+	}
+}
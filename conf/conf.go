@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/quasoft/pgconf/generic"
 )
@@ -18,6 +21,41 @@ const (
 // Keys with no values are not supported and the line containing it is ignored.
 var ErrKeyWithoutValue = fmt.Errorf("key without value")
 
+// ErrParseFailed is matched (with errors.Is) by errors of typed accessors (eg. IntK or BoolK) if
+// the key is found, but its value cannot be parsed as the requested type. The returned error is a
+// *ParseError, which wraps the underlying error (eg. a *strconv.NumError).
+var ErrParseFailed = fmt.Errorf("could not parse value")
+
+// ParseError is returned by typed accessors if the value of a key cannot be parsed.
+type ParseError struct {
+	Key   string // Name of the key
+	Value string // Dequoted value that could not be parsed
+	Err   error  // Underlying error
+}
+
+// Error returns a description of the error, including the name of the key.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("invalid value for key %s: %s", e.Key, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches target, which is true for ErrParseFailed.
+func (e *ParseError) Is(target error) bool {
+	return target == ErrParseFailed
+}
+
+// parseError returns a *ParseError for the key and value, or nil if err is nil.
+func parseError(key, value string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ParseError{key, value, err}
+}
+
 // NewParams creates param structure with defaults suitable for parsing of postgresql.conf files:
 //  - Whitespace:             space, tab, carriage return and equal sign
 //  - DefaultDelim: 		  =
@@ -26,6 +64,8 @@ var ErrKeyWithoutValue = fmt.Errorf("key without value")
 //  - DefaultQuote:           '
 //  - InlineComment:          #
 //  - AlwaysQuoteStrings:	  true
+//  - StrictColumns:          false
+//  - GroupDelims:            none
 func NewParams() generic.Params {
 	return generic.Params{
 		Whitespace:             " \t\r=",
@@ -35,29 +75,42 @@ func NewParams() generic.Params {
 		DefaultQuote:           '\'',
 		InlineComment:          '#',
 		AlwaysQuoteStrings:     true,
+		StrictColumns:          false,
+		GroupDelims:            "",
 	}
 }
 
 // Conf represents a PostgreSQL configuration file (postgresql.conf).
 type Conf struct {
 	*generic.Conf
+	strict        bool
+	expandEnv     bool
+	strictEnv     bool
+	sensitiveKeys []string
+	sources       []string          // Files the configuration was read from with OpenDir or OpenWithIncludes
+	appendTarget  string            // File for lines appended to a configuration read with OpenDir
+	includedDirs  []includedDir     // Directories read for include_dir directives by OpenWithIncludes
+	aliases       map[string]string // Canonical keys of aliases, keyed by normalized alias (see SetAlias)
+	changeLog     io.Writer         // Writer for logging changes (see SetChangeLog)
+	logging       bool              // Whether a change is being logged, to avoid logging nested setters
+	aliasing      bool              // Whether aliases of keys were resolved, to avoid resolving them in nested calls
+	mu            sync.RWMutex      // Guards the configuration in WithLock and WithReadLock
 }
 
 // New creates a new structure for reading/writing to postgresql.conf files with default params (see newParams).
 func New(conf string) *Conf {
 	return &Conf{
-		generic.New(conf, NewParams()),
+		Conf: generic.New(conf, NewParams()),
 	}
 }
 
 // Open opens and reads configuration from a file.
 func Open(filename string) (*Conf, error) {
-	content, err := ioutil.ReadFile(filename)
+	conf, err := generic.Open(filename, NewParams())
 	if err != nil {
-		return nil, fmt.Errorf("could not read file %s: %s", filename, err)
+		return nil, err
 	}
-	conf := string(content)
-	return New(conf), nil
+	return &Conf{Conf: conf}, nil
 }
 
 // OpenReader reads configuration from a reader.
@@ -70,14 +123,66 @@ func OpenReader(r io.Reader) (*Conf, error) {
 	return New(conf), nil
 }
 
+// OpenLimit opens and reads configuration from a file, failing with generic.ErrLimitExceeded
+// if the file is larger than maxBytes.
+func OpenLimit(filename string, maxBytes int64) (*Conf, error) {
+	conf, err := generic.OpenLimit(filename, maxBytes, NewParams())
+	if err != nil {
+		return nil, err
+	}
+	return &Conf{Conf: conf}, nil
+}
+
+// OpenReaderLimit reads configuration from a reader, failing with generic.ErrLimitExceeded
+// if the reader returns more than maxBytes.
+func OpenReaderLimit(r io.Reader, maxBytes int64) (*Conf, error) {
+	conf, err := generic.ReadAllLimit(r, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	return New(conf), nil
+}
+
+// OpenLimitLines opens and reads configuration from a file like OpenLimit, but also fails with
+// generic.ErrLineLimitExceeded if the file has more than maxLines lines.
+func OpenLimitLines(filename string, maxBytes int64, maxLines int) (*Conf, error) {
+	conf, err := generic.OpenLimitLines(filename, maxBytes, maxLines, NewParams())
+	if err != nil {
+		return nil, err
+	}
+	return &Conf{Conf: conf}, nil
+}
+
+// OpenReaderLimitLines reads configuration from a reader like OpenReaderLimit, but also fails
+// with generic.ErrLineLimitExceeded if the configuration has more than maxLines lines.
+func OpenReaderLimitLines(r io.Reader, maxBytes int64, maxLines int) (*Conf, error) {
+	conf, err := generic.OpenReaderLimitLines(r, maxBytes, maxLines, NewParams())
+	if err != nil {
+		return nil, err
+	}
+	return &Conf{Conf: conf}, nil
+}
+
+// NormalizeKey returns the key in the form used for matching parameter names, which are case
+// insensitive: lowercased and without surrounding whitespace.
+func NormalizeKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
 // LookupKey searches for a line that contains the given key, and if found,
-// returns a Row structure for that line.
+// returns a Row structure for that line. The key is normalized with NormalizeKey, and aliases
+// registered with SetAlias are resolved to their canonical key.
+// An unquoted value that contains whitespace (eg. log_line_prefix = %m [%p] %u@%d) is
+// treated as a single value spanning the rest of the line, up to any inline comment.
+// Only the first run of whitespace and equal signs separates the key from the value, so equal
+// signs inside the value (eg. search_path = a=b) are part of the value.
 func (c *Conf) LookupKey(key string) (*generic.Row, error) {
+	key = c.resolveAliasK(key)
 	var row *generic.Row
 	var offset int = 0
 	for {
 		// Find the last key that has any value
-		r, nextOffset, err := c.LookupRow(keyCol, key, true, offset)
+		r, nextOffset, err := c.LookupRow(keyCol, NormalizeKey(key), true, offset)
 		if err != nil {
 			break
 		}
@@ -89,7 +194,17 @@ func (c *Conf) LookupKey(key string) (*generic.Row, error) {
 	if row == nil {
 		return nil, generic.ErrKeyNotFound
 	}
-	return row, nil
+	return c.joinUnquotedValue(row), nil
+}
+
+// joinUnquotedValue returns the row with all columns after the key merged into a single value
+// column, unless the value starts with a quote character.
+func (c *Conf) joinUnquotedValue(row *generic.Row) *generic.Row {
+	value, err := c.Raw(row, valueCol)
+	if err != nil || strings.ContainsAny(value[:1], c.Params().Quotes) {
+		return row
+	}
+	return row.JoinColumns(valueCol)
 }
 
 // LookupOrAppendK searches for a line that contains the given key, and if found,
@@ -97,6 +212,7 @@ func (c *Conf) LookupKey(key string) (*generic.Row, error) {
 // If not found, a new row is created and appended with an empty value.
 // Searching for the key is case insensitive.
 func (c *Conf) LookupOrAppendK(key string) (*generic.Row, error) {
+	defer c.aliasScope(&key)()
 	row, err := c.LookupKey(key)
 	if err == generic.ErrKeyNotFound {
 		return c.Append([]string{key, "''"}...)
@@ -107,6 +223,52 @@ func (c *Conf) LookupOrAppendK(key string) (*generic.Row, error) {
 	return row, nil
 }
 
+// AppendFormatted adds a new line for the key, formatted according to the template, and returns
+// a Row structure describing the line appended. The template must contain the %k and %v
+// placeholders exactly once, which are replaced with the key and the raw value, while the rest of
+// the template is kept as is (eg. "%k\t= %v" or "%k = %v\t# comment"). This gives generators exact
+// control over the spacing and delimiters of the line.
+// Returns an error if the formatted line does not start with the key.
+func (c *Conf) AppendFormatted(key, value, template string) (row *generic.Row, err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("AppendFormatted", key)(&err)
+	if strings.Count(template, "%k") != 1 || strings.Count(template, "%v") != 1 {
+		return nil, fmt.Errorf("template %q must contain the placeholders %%k and %%v exactly once", template)
+	}
+	if strings.ContainsAny(template+key+value, "\r\n") {
+		return nil, fmt.Errorf("template %q must format a single line", template)
+	}
+	if err := c.validateK(key, value); err != nil {
+		return nil, err
+	}
+
+	line := strings.NewReplacer("%k", key, "%v", value).Replace(template)
+	if _, err := New(line).LookupKey(key); err != nil {
+		return nil, fmt.Errorf("template %q does not format a line with key %s", template, key)
+	}
+	return c.Append(line)
+}
+
+// HasK tests if the key is set to a value. A key that is present, but has no value, is treated
+// as absent (as by RawK, which returns ErrKeyWithoutValue for such keys).
+func (c *Conf) HasK(key string) bool {
+	key = NormalizeKey(c.resolveAliasK(key))
+	offset := 0
+	for {
+		// Inspect the tokens directly, without building errors for rows that have no value
+		row, nextOffset, err := c.LookupRow(keyCol, key, true, offset)
+		if err != nil {
+			return false
+		}
+		if row.HasColumn(valueCol) {
+			if token, _ := row.Token(valueCol); token.End > token.Start {
+				return true
+			}
+		}
+		offset = nextOffset
+	}
+}
+
 // RawK retrieves the raw value of the key, including any quotes.
 func (c *Conf) RawK(key string) (string, error) {
 	row, err := c.LookupKey(key)
@@ -122,47 +284,111 @@ func (c *Conf) RawK(key string) (string, error) {
 	return value, nil
 }
 
+// RawBytesK retrieves the raw value of the key like RawK, but as a slice of bytes, for callers
+// working with byte slices. The returned slice is a copy and can be modified. A zero-copy view is
+// deliberately not offered: the configuration is held in an immutable string, which a byte slice
+// could only alias through package unsafe, so that a caller writing to the slice would corrupt
+// the configuration and every string sharing its memory. Hot paths should use RawK, which does
+// not copy the value (see BenchmarkRawK and BenchmarkRawBytesK).
+func (c *Conf) RawBytesK(key string) ([]byte, error) {
+	row, err := c.LookupKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := c.RawBytes(row, valueCol)
+	if err != nil {
+		return nil, ErrKeyWithoutValue
+	}
+
+	return value, nil
+}
+
+// RawVerbatimK retrieves the raw value of the key exactly as written, including all tokens of an
+// unquoted multi-word value along with the whitespace between them.
+//
+// Deprecated: RawK returns multi-word values verbatim too (see LookupKey), so use RawK instead.
+func (c *Conf) RawVerbatimK(key string) (string, error) {
+	return c.RawK(key)
+}
+
 // StringK retrieves the value of the key as a dequoted string.
 // Removes the enclosing single quotes ('syslog' becomes just syslog),
 // unescapes doubled quoted ('''users''') and backslash-quoted ('\'users\'')
-// values.
+// values. If expansion of environment variables is enabled (see SetExpandEnv),
+// references to variables in the dequoted value are expanded.
 func (c *Conf) StringK(key string) (string, error) {
 	row, err := c.LookupKey(key)
 	if err != nil {
 		return "", err
 	}
 
-	return c.String(row, valueCol)
+	value, err := c.String(row, valueCol)
+	if err != nil {
+		return "", err
+	}
+
+	return c.expandEnvK(key, value)
+}
+
+// FirstPresentK retrieves the dequoted value of the first of the given keys that is set, in the
+// order given. Useful for settings that were renamed between PostgreSQL versions
+// (eg. FirstPresentK("max_wal_size", "checkpoint_segments")).
+// Returns generic.ErrKeyNotFound if none of the keys is set.
+func (c *Conf) FirstPresentK(keys ...string) (string, error) {
+	for _, key := range keys {
+		value, err := c.StringK(key)
+		if err != generic.ErrKeyNotFound {
+			return value, err
+		}
+	}
+	return "", generic.ErrKeyNotFound
+}
+
+// AsNonEmptyStringK retrieves the value of the key as a dequoted string, along with a flag that
+// is false if the value is empty (eg. ssl_ca_file = ''), which for many settings means that the
+// feature is disabled or the default is used. Returns an error only if the key is not set.
+func (c *Conf) AsNonEmptyStringK(key string) (string, bool, error) {
+	value, err := c.StringK(key)
+	if err != nil {
+		return "", false, err
+	}
+	return value, value != "", nil
 }
 
 // IntK retrieves the value of the key as a dequoted integer.
+// Returns generic.ErrKeyNotFound if the key is not set, or an error matching ErrParseFailed if
+// the value is not an integer.
 func (c *Conf) IntK(key string) (int, error) {
-	row, err := c.LookupKey(key)
+	value, err := c.StringK(key) // Read as string first to dequote and expand the value
 	if err != nil {
 		return 0, err
 	}
 
-	return c.Int(row, valueCol)
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	return n, parseError(key, value, err)
 }
 
 // Int64K retrieves the value of the key as a dequoted int64.
 func (c *Conf) Int64K(key string) (int64, error) {
-	row, err := c.LookupKey(key)
+	value, err := c.StringK(key) // Read as string first to dequote and expand the value
 	if err != nil {
 		return 0, err
 	}
 
-	return c.Int64(row, valueCol)
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	return n, parseError(key, value, err)
 }
 
 // Float64K retrieves the value of the key as a dequoted floating point number.
 func (c *Conf) Float64K(key string) (float64, error) {
-	row, err := c.LookupKey(key)
+	value, err := c.StringK(key) // Read as string first to dequote and expand the value
 	if err != nil {
 		return 0, err
 	}
 
-	return c.Float64(row, valueCol)
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	return f, parseError(key, value, err)
 }
 
 // BoolK retrieves the value of the key as a boolean.
@@ -174,30 +400,174 @@ func (c *Conf) BoolK(key string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	b, ok := parseBool(value)
+	if !ok {
+		return false, parseError(key, value, fmt.Errorf("unknown boolean value %q", value))
+	}
+	return b, nil
+}
+
+// AsBoolTriStateK retrieves the value of the key as a pointer to a boolean, or nil if the key
+// is not set. Unlike BoolK, a missing key is not an error. An error is returned only if the key
+// is set, but its value is not a boolean.
+func (c *Conf) AsBoolTriStateK(key string) (*bool, error) {
+	b, err := c.BoolK(key)
+	if err == generic.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// BoolKOrDefault retrieves the value of the key as a boolean like BoolK, or the default value of
+// the key from the GUC registry, if the key is not set. This is the effective value, including
+// built-in defaults. Returns generic.ErrKeyNotFound if the key is neither set nor known to
+// the registry.
+func (c *Conf) BoolKOrDefault(key string) (bool, error) {
+	b, err := c.BoolK(key)
+	if err != generic.ErrKeyNotFound {
+		return b, err
+	}
+	g, ok := LookupGUC(c.resolveAliasK(key))
+	if !ok {
+		return false, err
+	}
+	b, ok = parseBool(g.Default)
+	if !ok {
+		return false, fmt.Errorf("default value of key %s is not a boolean", key)
+	}
+	return b, nil
+}
+
+// AsPgBoolK retrieves the value of the key as a boolean, following the exact rules of PostgreSQL's
+// parse_bool: the value must be on, off, 1, 0 or a prefix of true, false, yes or no. Prefixes of
+// on and off must be at least two characters long to be unambiguous. Case does not matter.
+// Unlike BoolK, words that merely start with the right letter (eg. fast or northbound) are rejected.
+func (c *Conf) AsPgBoolK(key string) (bool, error) {
+	value, err := c.StringK(key) // Read as string first to dequote the value
+	if err != nil {
+		return false, err
+	}
+	b, ok := parsePgBool(value)
+	if !ok {
+		return false, parseError(key, value, fmt.Errorf("unknown boolean value %q", value))
+	}
+	return b, nil
+}
+
+// parsePgBool parses the dequoted value as a boolean, following the rules documented at AsPgBoolK.
+// The second return value is false if the value is not a boolean.
+func parsePgBool(value string) (bool, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	isPrefix := func(word string, minLen int) bool {
+		return len(value) >= minLen && strings.HasPrefix(word, value)
+	}
+	switch {
+	case isPrefix("true", 1), isPrefix("yes", 1), isPrefix("on", 2), value == "1":
+		return true, true
+	case isPrefix("false", 1), isPrefix("no", 1), isPrefix("off", 2), value == "0":
+		return false, true
+	}
+	return false, false
+}
+
+// CanonicalBoolStringK retrieves the value of a boolean key as either "on" or "off", regardless
+// of how the value is stored (eg. yes, true or 1 are all returned as "on").
+func (c *Conf) CanonicalBoolStringK(key string) (string, error) {
+	b, err := c.BoolK(key)
+	if err != nil {
+		return "", err
+	}
+	if b {
+		return "on", nil
+	}
+	return "off", nil
+}
+
+// Styles in which boolean values can be stored, as returned by BoolStyleK
+const (
+	BoolStyleOnOff     = "on_off"     // on/off (see SetOnOffK)
+	BoolStyleTrueFalse = "true_false" // true/false (see SetTrueFalseK)
+	BoolStyleYesNo     = "yes_no"     // yes/no (see SetYesNoK)
+	BoolStyleOneZero   = "one_zero"   // 1/0
+)
+
+// BoolStyleK returns the style in which the boolean value of the key is stored: BoolStyleOnOff,
+// BoolStyleTrueFalse, BoolStyleYesNo or BoolStyleOneZero. Prefixes (eg. t or n) are reported with
+// the style of the word they abbreviate. Useful for writing a value back in the same style.
+// Returns an error if the value is not a boolean.
+func (c *Conf) BoolStyleK(key string) (string, error) {
+	value, err := c.StringK(key) // Read as string first to dequote the value
+	if err != nil {
+		return "", err
+	}
+	if _, ok := parseBool(value); !ok {
+		return "", fmt.Errorf("unknown boolean value for key %s", key)
+	}
+	switch value = strings.ToLower(strings.TrimSpace(value)); {
+	case value == "on" || strings.HasPrefix(value, "of"):
+		return BoolStyleOnOff, nil
+	case strings.HasPrefix(value, "t") || strings.HasPrefix(value, "f"):
+		return BoolStyleTrueFalse, nil
+	case strings.HasPrefix(value, "y") || strings.HasPrefix(value, "n"):
+		return BoolStyleYesNo, nil
+	}
+	return BoolStyleOneZero, nil
+}
+
+// AsBoolSliceK retrieves the value of the key as a comma-separated list of booleans
+// (eg. 'on,off,true'). Each element is parsed with the rules documented at BoolK.
+// Returns an error if any element is not a boolean.
+func (c *Conf) AsBoolSliceK(key string) ([]bool, error) {
+	value, err := c.StringK(key) // Read as string first to dequote the value
+	if err != nil {
+		return nil, err
+	}
+	var result []bool
+	for _, elem := range strings.Split(value, ",") {
+		b, ok := parseBool(elem)
+		if !ok {
+			return nil, parseError(key, value, fmt.Errorf("unknown boolean value %q in list", strings.TrimSpace(elem)))
+		}
+		result = append(result, b)
+	}
+	return result, nil
+}
+
+// parseBool parses the dequoted value as a boolean, following the rules documented at BoolK.
+// The second return value is false if the value is not a boolean.
+func parseBool(value string) (bool, bool) {
 	value = strings.TrimSpace(value)
 	value = strings.ToLower(value)
 	if value == "on" {
-		return true, nil
+		return true, true
 	} else if strings.HasPrefix(value, "of") {
-		return false, nil
+		return false, true
 	} else if strings.HasPrefix(value, "t") {
-		return true, nil
+		return true, true
 	} else if strings.HasPrefix(value, "f") {
-		return false, nil
+		return false, true
 	} else if strings.HasPrefix(value, "y") {
-		return true, nil
+		return true, true
 	} else if strings.HasPrefix(value, "n") {
-		return false, nil
+		return false, true
 	} else if value == "1" {
-		return true, nil
+		return true, true
 	} else if value == "0" {
-		return false, nil
+		return false, true
 	}
-	return false, fmt.Errorf("unknown boolean value for key %s", key)
+	return false, false
 }
 
 // SetRawK replaces the raw value of the specified key (including any quotes).
-func (c *Conf) SetRawK(key string, value string) error {
+func (c *Conf) SetRawK(key string, value string) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetRawK", key)(&err)
+	if err := c.validateK(key, c.Dequote(value)); err != nil {
+		return err
+	}
 	row, err := c.LookupOrAppendK(key)
 	if err != nil {
 		return err
@@ -205,8 +575,110 @@ func (c *Conf) SetRawK(key string, value string) error {
 	return c.SetRaw(row, valueCol, value)
 }
 
+// SetRawKDelta returns the net change in the length of the configuration (in bytes) that calling
+// SetRawK with the same arguments would cause, without modifying the configuration. For an existing
+// key this is the length of the new value minus the length of the old one, while for a new key it
+// is the length of the appended line (including an EOL character added before it, if any).
+func (c *Conf) SetRawKDelta(key string, value string) (int, error) {
+	clone := c.Clone()
+	if err := clone.SetRawK(key, value); err != nil {
+		return 0, err
+	}
+	return len(clone.All()) - len(c.All()), nil
+}
+
+// SetRawKMaxLen replaces the raw value of the specified key (including any quotes) like SetRawK,
+// unless the resulting line would be longer than maxLen characters, in which case an error is
+// returned and the configuration is left unchanged.
+func (c *Conf) SetRawKMaxLen(key string, value string, maxLen int) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetRawKMaxLen", key)(&err)
+	clone := c.Clone()
+	clone.aliases = nil // The key is already resolved
+	if err := clone.SetRawK(key, value); err != nil {
+		return err
+	}
+	number, err := clone.EffectiveLineK(key)
+	if err != nil {
+		return err
+	}
+	line, err := clone.LineAt(number)
+	if err != nil {
+		return err
+	}
+	if n := utf8.RuneCountInString(strings.TrimSuffix(line.Text, "\r")); n > maxLen {
+		return fmt.Errorf("line for key %s would be %d characters long, want at most %d", key, n, maxLen)
+	}
+	return c.SetRawK(key, value)
+}
+
+// SetRawAfterK replaces the raw value of the specified key (including any quotes). If the key is not
+// set, it is inserted on a new line directly below the line holding afterKey, using the same
+// indentation. If afterKey is not set either, the key is appended at the end.
+// Useful for keeping related settings together (eg. max_wal_size after min_wal_size).
+func (c *Conf) SetRawAfterK(afterKey string, key string, value string) (err error) {
+	defer c.aliasScope(&afterKey, &key)()
+	defer c.logChangeK("SetRawAfterK", key)(&err)
+	if _, err := c.LookupKey(key); err == nil {
+		return c.SetRawK(key, value)
+	}
+	afterRow, err := c.LookupKey(afterKey)
+	if err == generic.ErrKeyNotFound {
+		return c.SetRawK(key, value)
+	} else if err != nil {
+		return err
+	}
+	if err := c.validateK(key, c.Dequote(value)); err != nil {
+		return err
+	}
+	_, err = c.InsertAfter(afterRow, key, value)
+	return err
+}
+
+// SetRawAtLineK replaces the raw value on the line with the given 1-based line number, regardless
+// of which key the line holds. Useful for files with intentional duplicates, where the last-wins
+// resolution of LookupKey is not what the caller wants.
+// Returns ErrKeyWithoutValue if the line has no value.
+func (c *Conf) SetRawAtLineK(lineNumber int, value string) (err error) {
+	line, err := c.LineAt(lineNumber)
+	if err != nil {
+		return err
+	}
+	row, err := c.RowOf(line)
+	if err != nil {
+		return fmt.Errorf("line %d contains no key: %s", lineNumber, err)
+	}
+	if !row.HasColumn(valueCol) {
+		return ErrKeyWithoutValue
+	}
+	row = c.joinUnquotedValue(row)
+	key, _ := c.Raw(row, keyCol)
+	defer c.logChange("SetRawAtLineK", key, func(string) string { return c.rawAtLine(lineNumber) })(&err)
+	return c.SetRaw(row, valueCol, value)
+}
+
+// rawAtLine retrieves the raw value on the line with the given 1-based line number, or an empty
+// string if the line has no value.
+func (c *Conf) rawAtLine(lineNumber int) string {
+	line, err := c.LineAt(lineNumber)
+	if err != nil {
+		return ""
+	}
+	row, err := c.RowOf(line)
+	if err != nil || !row.HasColumn(valueCol) {
+		return ""
+	}
+	value, _ := c.Raw(c.joinUnquotedValue(row), valueCol)
+	return value
+}
+
 // SetStringK replaces the value of the specified key, enclosing it in single quotes.
-func (c *Conf) SetStringK(key string, value string) error {
+func (c *Conf) SetStringK(key string, value string) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetStringK", key)(&err)
+	if err := c.validateK(key, value); err != nil {
+		return err
+	}
 	row, err := c.LookupOrAppendK(key)
 	if err != nil {
 		return err
@@ -215,7 +687,12 @@ func (c *Conf) SetStringK(key string, value string) error {
 }
 
 // SetIntK replaces the value of the specified key with an unquoted integer value.
-func (c *Conf) SetIntK(key string, value int) error {
+func (c *Conf) SetIntK(key string, value int) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetIntK", key)(&err)
+	if err := c.validateK(key, strconv.Itoa(value)); err != nil {
+		return err
+	}
 	row, err := c.LookupOrAppendK(key)
 	if err != nil {
 		return err
@@ -224,7 +701,12 @@ func (c *Conf) SetIntK(key string, value int) error {
 }
 
 // SetInt64K replaces the value of the specified key with an unquoted int64 value.
-func (c *Conf) SetInt64K(key string, value int64) error {
+func (c *Conf) SetInt64K(key string, value int64) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetInt64K", key)(&err)
+	if err := c.validateK(key, strconv.FormatInt(value, 10)); err != nil {
+		return err
+	}
 	row, err := c.LookupOrAppendK(key)
 	if err != nil {
 		return err
@@ -232,11 +714,30 @@ func (c *Conf) SetInt64K(key string, value int64) error {
 	return c.SetInt64(row, valueCol, value)
 }
 
+// SetInt64ValidatedK replaces the value of the specified key with an unquoted int64 value, after
+// checking that the value is within the documented range of the parameter in the GUC registry,
+// regardless of whether strict validation is enabled. Keys unknown to the registry are not validated.
+func (c *Conf) SetInt64ValidatedK(key string, value int64) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetInt64ValidatedK", key)(&err)
+	if g, ok := LookupGUC(key); ok {
+		if err := g.Validate(strconv.FormatInt(value, 10)); err != nil {
+			return err
+		}
+	}
+	return c.SetInt64K(key, value)
+}
+
 // SetFloat64K replaces the value of the specified key with a floating point number,
 // while preserving whitespace on line.
 // Outputs a string with the smallest number of digits needed to represent the value.
 // If you want precision of your choice, or to enclose the value in quotes, use SetRawK instead.
-func (c *Conf) SetFloat64K(key string, value float64) error {
+func (c *Conf) SetFloat64K(key string, value float64) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetFloat64K", key)(&err)
+	if err := c.validateK(key, strconv.FormatFloat(value, 'f', -1, 64)); err != nil {
+		return err
+	}
 	row, err := c.LookupOrAppendK(key)
 	if err != nil {
 		return err
@@ -244,8 +745,38 @@ func (c *Conf) SetFloat64K(key string, value float64) error {
 	return c.SetFloat64(row, valueCol, value)
 }
 
+// AppendDocumentedK appends a new line with the given key and raw value. If the key is known
+// to the GUC registry, the line is preceded by a comment with the short description of the setting.
+// If the line cannot be appended, the configuration is left unchanged.
+func (c *Conf) AppendDocumentedK(key string, value string) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("AppendDocumentedK", key)(&err)
+	// Append to a copy first, so that a line that cannot be appended leaves the comment out too
+	if _, err := c.Clone().Append(key, value); err != nil {
+		return err
+	}
+	if g, ok := LookupGUC(key); ok {
+		c.AppendComment(g.Description)
+	}
+	_, err = c.Append(key, value)
+	return err
+}
+
+// SetFloat64SciK replaces the value of the specified key with a floating point number in scientific
+// notation (eg. 1.5e-03), with prec digits after the decimal point. A prec of -1 uses the smallest
+// number of digits necessary to represent the value exactly.
+// Useful for very small or very large cost factors.
+func (c *Conf) SetFloat64SciK(key string, value float64, prec int) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetFloat64SciK", key)(&err)
+	raw := strconv.FormatFloat(value, 'e', prec, 64)
+	return c.SetRawK(key, raw)
+}
+
 // SetTrueFalseK replaces the value of the specified key with true or false.
-func (c *Conf) SetTrueFalseK(key string, value bool) error {
+func (c *Conf) SetTrueFalseK(key string, value bool) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetTrueFalseK", key)(&err)
 	var raw string
 	if value {
 		raw = "true"
@@ -256,7 +787,9 @@ func (c *Conf) SetTrueFalseK(key string, value bool) error {
 }
 
 // SetOnOffK replaces the value of the specified key with on or off.
-func (c *Conf) SetOnOffK(key string, value bool) error {
+func (c *Conf) SetOnOffK(key string, value bool) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetOnOffK", key)(&err)
 	var raw string
 	if value {
 		raw = "on"
@@ -267,7 +800,9 @@ func (c *Conf) SetOnOffK(key string, value bool) error {
 }
 
 // SetYesNoK replaces the value of the specified key with yes or no.
-func (c *Conf) SetYesNoK(key string, value bool) error {
+func (c *Conf) SetYesNoK(key string, value bool) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetYesNoK", key)(&err)
 	var raw string
 	if value {
 		raw = "yes"
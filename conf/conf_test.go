@@ -2,13 +2,17 @@ package conf_test
 
 import (
 	"bufio"
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/quasoft/pgconf/conf"
+	"github.com/quasoft/pgconf/generic"
 )
 
 func openTestFile(t *testing.T, testFile string) *conf.Conf {
@@ -31,7 +35,7 @@ func openEmptyFile(t *testing.T) *conf.Conf {
 	return openTestFile(t, "empty.conf")
 }
 
-func readTestFile(t *testing.T, testFile string) string {
+func readTestFile(t testing.TB, testFile string) string {
 	filename := filepath.Join("testdata", testFile)
 	bytes, err := ioutil.ReadFile(filename)
 	if err != nil {
@@ -77,6 +81,31 @@ func TestOpenReader(t *testing.T) {
 	}
 }
 
+func TestHasK(t *testing.T) {
+	conf := openConfFile(t)
+	conf.SetAlias("max_conns", "max_connections")
+
+	tests := []struct {
+		name string
+		key  string
+		want bool
+	}{
+		{"Nonexisting key", "there_is_no_such_key", false},
+		{"Alias of key", "max_conns", true},
+		{"Key without value", "invalid_key_without_value", false},
+		{"Quoted string value", "listen_addresses", true},
+		{"Mixed case key", "Max_Connections", true},
+		{"No equal sign", "log_connections", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := conf.HasK(tt.key); got != tt.want {
+				t.Errorf("HasK(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRawK(t *testing.T) {
 	conf := openConfFile(t)
 
@@ -386,7 +415,7 @@ func TestSetIntK(t *testing.T) {
 				got := readLine(t, content, tt.lineNumber)
 				want := readLine(t, wantContent, tt.lineNumber)
 				if got != want {
-					t.Errorf("SetIntK(%q, %q) = got line #%d = %q, want %q", tt.key, tt.value, tt.lineNumber, got, want)
+					t.Errorf("SetIntK(%q, %d) = got line #%d = %q, want %q", tt.key, tt.value, tt.lineNumber, got, want)
 				}
 			}
 		})
@@ -414,7 +443,7 @@ func TestSetInt64K(t *testing.T) {
 				got := readLine(t, content, tt.lineNumber)
 				want := readLine(t, wantContent, tt.lineNumber)
 				if got != want {
-					t.Errorf("SetInt64K(%q, %q) = got line #%d = %q, want %q", tt.key, tt.value, tt.lineNumber, got, want)
+					t.Errorf("SetInt64K(%q, %d) = got line #%d = %q, want %q", tt.key, tt.value, tt.lineNumber, got, want)
 				}
 			}
 		})
@@ -536,3 +565,1141 @@ func TestSetYesNoK(t *testing.T) {
 		})
 	}
 }
+
+func TestAppendDocumentedK(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  string
+	}{
+		{"Known key", "work_mem", "64MB", "# Sets the maximum memory to be used for query workspaces.\nwork_mem = 64MB"},
+		{"Unknown key", "my.custom_setting", "'x'", "my.custom_setting = 'x'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := conf.New("port = 5432\n")
+			err := conf.AppendDocumentedK(tt.key, tt.value)
+			if err != nil {
+				t.Fatalf("AppendDocumentedK(%q, %q) errored with '%s', wanted no error", tt.key, tt.value, err)
+			}
+			want := "port = 5432\n" + tt.want
+			if got := conf.All(); got != want {
+				t.Errorf("AppendDocumentedK(%q, %q) = %q, want %q", tt.key, tt.value, got, want)
+			}
+		})
+	}
+}
+
+func TestAppendDocumentedK_Failed(t *testing.T) {
+	c := conf.New("port = 5432\n")
+	params := c.Params()
+	params.StrictColumns = true
+	c.SetParams(params)
+	if err := c.AppendDocumentedK("work_mem", "64 MB"); err == nil {
+		t.Errorf("AppendDocumentedK() with an unquoted space did not error, wanted error")
+	}
+	if got := c.All(); got != "port = 5432\n" {
+		t.Errorf("AppendDocumentedK() changed configuration to %q, want %q", got, "port = 5432\n")
+	}
+}
+
+func TestSetRawAtLineK(t *testing.T) {
+	content := "work_mem = 4MB\n" +
+		"# comment\n" +
+		"work_mem = 8MB  # duplicate\n" +
+		"work_mem = 16MB\n" +
+		"invalid_key_without_value\n" +
+		"log_line_prefix = %m [%p]  # prefix\n"
+	tests := []struct {
+		name       string
+		lineNumber int
+		value      string
+		want       string
+		noerror    bool
+	}{
+		{"Middle duplicate", 3, "32MB", "work_mem = 4MB\n# comment\nwork_mem = 32MB  # duplicate\nwork_mem = 16MB\ninvalid_key_without_value\nlog_line_prefix = %m [%p]  # prefix\n", true},
+		{"First duplicate", 1, "1MB", "work_mem = 1MB\n# comment\nwork_mem = 8MB  # duplicate\nwork_mem = 16MB\ninvalid_key_without_value\nlog_line_prefix = %m [%p]  # prefix\n", true},
+		{"Multi-word unquoted value", 6, "'%m '", "work_mem = 4MB\n# comment\nwork_mem = 8MB  # duplicate\nwork_mem = 16MB\ninvalid_key_without_value\nlog_line_prefix = '%m '  # prefix\n", true},
+		{"Comment line", 2, "1MB", content, false},
+		{"Key without value", 5, "1MB", content, false},
+		{"Line out of range", 7, "1MB", content, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := conf.New(content)
+			err := conf.SetRawAtLineK(tt.lineNumber, tt.value)
+			if err != nil && tt.noerror {
+				t.Errorf("SetRawAtLineK(%d, %q) errored with '%s', wanted no error", tt.lineNumber, tt.value, err)
+			} else if err == nil && !tt.noerror {
+				t.Errorf("SetRawAtLineK(%d, %q) did not error, wanted error", tt.lineNumber, tt.value)
+			} else if got := conf.All(); got != tt.want {
+				t.Errorf("SetRawAtLineK(%d, %q) = %q, want %q", tt.lineNumber, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenLimit(t *testing.T) {
+	filename := filepath.Join("testdata", "postgresql.conf")
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatalf(`Stat("testdata/postgresql.conf") failed: %s`, err)
+	}
+	size := info.Size()
+
+	tests := []struct {
+		name     string
+		maxBytes int64
+		noerror  bool
+	}{
+		{"Under limit", size + 1, true},
+		{"Exactly at limit", size, true},
+		{"Over limit", size - 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := conf.OpenLimit(filename, tt.maxBytes)
+			if err != nil && tt.noerror {
+				t.Errorf("OpenLimit(%q, %d) errored with '%s', wanted no error", filename, tt.maxBytes, err)
+			} else if err == nil && !tt.noerror {
+				t.Errorf("OpenLimit(%q, %d) did not error, wanted error", filename, tt.maxBytes)
+			} else if !tt.noerror && err != generic.ErrLimitExceeded {
+				t.Errorf("OpenLimit(%q, %d) errored with '%s', want '%s'", filename, tt.maxBytes, err, generic.ErrLimitExceeded)
+			} else if tt.noerror && c.All() != readTestFile(t, "postgresql.conf") {
+				t.Errorf("OpenLimit(%q, %d) did not load the whole file", filename, tt.maxBytes)
+			} else if tt.noerror && c.Filename() != filename {
+				t.Errorf("OpenLimit(%q, %d) set Filename() to %q, want %q", filename, tt.maxBytes, c.Filename(), filename)
+			}
+		})
+	}
+}
+
+func TestOpenReaderLimit(t *testing.T) {
+	_, err := conf.OpenReaderLimit(strings.NewReader("port = 5432\n"), 5)
+	if err != generic.ErrLimitExceeded {
+		t.Errorf("OpenReaderLimit() errored with '%v', want '%s'", err, generic.ErrLimitExceeded)
+	}
+}
+
+func TestOpenLimitLines(t *testing.T) {
+	filename := filepath.Join("testdata", "postgresql.conf")
+	lines := len(conf.New(readTestFile(t, "postgresql.conf")).Lines())
+
+	tests := []struct {
+		name     string
+		maxLines int
+		noerror  bool
+	}{
+		{"Under limit", lines + 1, true},
+		{"Exactly at limit", lines, true},
+		{"Over limit", lines - 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := conf.OpenLimitLines(filename, 1<<20, tt.maxLines)
+			if err != nil && tt.noerror {
+				t.Errorf("OpenLimitLines(%q, %d) errored with '%s', wanted no error", filename, tt.maxLines, err)
+			} else if err == nil && !tt.noerror {
+				t.Errorf("OpenLimitLines(%q, %d) did not error, wanted error", filename, tt.maxLines)
+			} else if !tt.noerror && err != generic.ErrLineLimitExceeded {
+				t.Errorf("OpenLimitLines(%q, %d) errored with '%s', want '%s'", filename, tt.maxLines, err, generic.ErrLineLimitExceeded)
+			} else if tt.noerror && c.All() != readTestFile(t, "postgresql.conf") {
+				t.Errorf("OpenLimitLines(%q, %d) did not load the whole file", filename, tt.maxLines)
+			} else if tt.noerror && c.Filename() != filename {
+				t.Errorf("OpenLimitLines(%q, %d) set Filename() to %q, want %q", filename, tt.maxLines, c.Filename(), filename)
+			}
+		})
+	}
+}
+
+func TestOpenReaderLimitLines(t *testing.T) {
+	_, err := conf.OpenReaderLimitLines(strings.NewReader("port = 5432\n"), 5, 10)
+	if err != generic.ErrLimitExceeded {
+		t.Errorf("OpenReaderLimitLines() errored with '%v', want '%s'", err, generic.ErrLimitExceeded)
+	}
+	_, err = conf.OpenReaderLimitLines(strings.NewReader("port = 5432\n\n\nwork_mem = 4MB"), 100, 3)
+	if err != generic.ErrLineLimitExceeded {
+		t.Errorf("OpenReaderLimitLines() errored with '%v', want '%s'", err, generic.ErrLineLimitExceeded)
+	}
+	if _, err = conf.OpenReaderLimitLines(strings.NewReader("port = 5432\n\n\n"), 100, 3); err != nil {
+		t.Errorf("OpenReaderLimitLines() errored with '%s', wanted no error", err)
+	}
+}
+
+func TestSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgconf")
+	if err != nil {
+		t.Fatalf("TempDir() failed: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "postgresql.conf")
+	err = ioutil.WriteFile(filename, []byte("port = 5432\n"), 0600)
+	if err != nil {
+		t.Fatalf("WriteFile(%q) failed: %s", filename, err)
+	}
+
+	c, err := conf.Open(filename)
+	if err != nil {
+		t.Fatalf("Open(%q) failed: %s", filename, err)
+	}
+	if c.Filename() != filename {
+		t.Errorf("Filename() = %q, want %q", c.Filename(), filename)
+	}
+	c.SetIntK("port", 6000)
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save() errored with '%s', wanted no error", err)
+	}
+
+	got, _ := ioutil.ReadFile(filename)
+	if string(got) != "port = 6000\n" {
+		t.Errorf("Save() wrote %q, want %q", got, "port = 6000\n")
+	}
+	info, _ := os.Stat(filename)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Save() changed permissions to %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+
+	c.SetIntK("port", 7000)
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() errored with '%s', wanted no error", err)
+	}
+	if c.All() != "port = 6000\n" {
+		t.Errorf("Reload() = %q, want %q", c.All(), "port = 6000\n")
+	}
+}
+
+func TestSave_TrailingBlankLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgconf")
+	if err != nil {
+		t.Fatalf("TempDir() failed: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"Blank lines", "port = 5432\nwork_mem = 4MB\n\n\n", "port = 6000\nwork_mem = 4MB\n\n\n"},
+		{"Whitespace lines", "port = 5432\nwork_mem = 4MB\n \n\t", "port = 6000\nwork_mem = 4MB\n \n\t"},
+		{"CRLF", "port = 5432\r\nwork_mem = 4MB\r\n\r\n\r\n", "port = 6000\r\nwork_mem = 4MB\r\n\r\n\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(dir, "postgresql.conf")
+			if err := ioutil.WriteFile(filename, []byte(tt.content), 0600); err != nil {
+				t.Fatalf("WriteFile(%q) failed: %s", filename, err)
+			}
+			c, err := conf.Open(filename)
+			if err != nil {
+				t.Fatalf("Open(%q) failed: %s", filename, err)
+			}
+			if err := c.SetRawK("port", "6000"); err != nil {
+				t.Fatalf("SetRawK() errored with '%s', wanted no error", err)
+			}
+			if err := c.Save(); err != nil {
+				t.Fatalf("Save() errored with '%s', wanted no error", err)
+			}
+			if got, _ := ioutil.ReadFile(filename); string(got) != tt.want {
+				t.Errorf("Save() wrote %q, want %q", got, tt.want)
+			}
+
+			c, err = conf.OpenDir(dir)
+			if err != nil {
+				t.Fatalf("OpenDir(%q) failed: %s", dir, err)
+			}
+			if err := c.SetRawK("port", "6000"); err != nil {
+				t.Fatalf("SetRawK() errored with '%s', wanted no error", err)
+			}
+			if err := c.SaveSources(); err != nil {
+				t.Fatalf("SaveSources() errored with '%s', wanted no error", err)
+			}
+			want := tt.want
+			if !strings.HasSuffix(want, "\n") {
+				want += "\n" // SaveSources ends every file with an EOL character
+			}
+			if got, _ := ioutil.ReadFile(filename); string(got) != want {
+				t.Errorf("SaveSources() wrote %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSave_NoBackingFile(t *testing.T) {
+	c := conf.New("port = 5432\n")
+	if err := c.Save(); err != generic.ErrNoBackingFile {
+		t.Errorf("Save() errored with '%v', want '%s'", err, generic.ErrNoBackingFile)
+	}
+	if err := c.Reload(); err != generic.ErrNoBackingFile {
+		t.Errorf("Reload() errored with '%v', want '%s'", err, generic.ErrNoBackingFile)
+	}
+
+	c, err := conf.OpenReader(strings.NewReader("port = 5432\n"))
+	if err != nil {
+		t.Fatalf("OpenReader() failed: %s", err)
+	}
+	if err := c.Save(); err != generic.ErrNoBackingFile {
+		t.Errorf("Save() errored with '%v', want '%s'", err, generic.ErrNoBackingFile)
+	}
+}
+
+func TestAsBoolTriStateK(t *testing.T) {
+	conf := openConfFile(t)
+
+	on := true
+	tests := []struct {
+		name    string
+		key     string
+		want    *bool
+		noerror bool
+	}{
+		{"Absent key", "there_is_no_such_key", nil, true},
+		{"On value", "ssl", &on, true},
+		{"Garbage value", "log_destination", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := conf.AsBoolTriStateK(tt.key)
+			if err != nil && tt.noerror {
+				t.Errorf("AsBoolTriStateK(%q) errored with '%s', wanted no error", tt.key, err)
+			} else if err == nil && !tt.noerror {
+				t.Errorf("AsBoolTriStateK(%q) did not error, wanted error", tt.key)
+			} else if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("AsBoolTriStateK(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRawVerbatimK(t *testing.T) {
+	c := conf.New("log_line_prefix = %m   [%p]  %u@%d   # comment\n" +
+		"listen_addresses = '*'\t\n" +
+		"port = 5432")
+
+	tests := []struct {
+		name    string
+		key     string
+		want    string
+		noerror bool
+	}{
+		{"Multi-word unquoted value", "log_line_prefix", "%m   [%p]  %u@%d", true},
+		{"Quoted value with trailing whitespace", "listen_addresses", "'*'", true},
+		{"Value at end of file", "port", "5432", true},
+		{"Nonexisting key", "there_is_no_such_key", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.RawVerbatimK(tt.key)
+			if err != nil && tt.noerror {
+				t.Errorf("RawVerbatimK(%q) errored with '%s', wanted no error", tt.key, err)
+			} else if err == nil && !tt.noerror {
+				t.Errorf("RawVerbatimK(%q) did not error, wanted error", tt.key)
+			} else if got != tt.want {
+				t.Errorf("RawVerbatimK(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetRawAfterK(t *testing.T) {
+	content := "# WAL\n" +
+		"\tmin_wal_size = 80MB  # comment\n" +
+		"checkpoint_timeout = 5min\n" +
+		"port = 5432"
+	tests := []struct {
+		name     string
+		afterKey string
+		key      string
+		value    string
+		want     string
+	}{
+		{"Insert after key", "min_wal_size", "max_wal_size", "1GB", "# WAL\n\tmin_wal_size = 80MB  # comment\n\tmax_wal_size = 1GB\ncheckpoint_timeout = 5min\nport = 5432"},
+		{"Insert after last line", "port", "max_wal_size", "1GB", "# WAL\n\tmin_wal_size = 80MB  # comment\ncheckpoint_timeout = 5min\nport = 5432\nmax_wal_size = 1GB"},
+		{"Update existing key", "min_wal_size", "port", "6000", "# WAL\n\tmin_wal_size = 80MB  # comment\ncheckpoint_timeout = 5min\nport = 6000"},
+		{"Missing afterKey", "there_is_no_such_key", "max_wal_size", "1GB", content + "\nmax_wal_size = 1GB"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(content)
+			err := c.SetRawAfterK(tt.afterKey, tt.key, tt.value)
+			if err != nil {
+				t.Fatalf("SetRawAfterK(%q, %q, %q) errored with '%s', wanted no error", tt.afterKey, tt.key, tt.value, err)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("SetRawAfterK(%q, %q, %q) = %q, want %q", tt.afterKey, tt.key, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringK_QuotedWhitespace(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"Spaces in single quotes", "cluster_name = '   '\n", "   "},
+		{"Spaces in double quotes", "cluster_name = \"   \"\n", "   "},
+		{"Tab in quotes", "cluster_name = '\t'\n", "\t"},
+		{"No EOL", "cluster_name = '   '", "   "},
+		{"Inline comment without whitespace", "cluster_name='   '# comment\n", "   "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(tt.content)
+			got, err := c.StringK("cluster_name")
+			if err != nil {
+				t.Errorf("StringK(\"cluster_name\") errored with '%s', wanted no error", err)
+			} else if got != tt.want {
+				t.Errorf("StringK(\"cluster_name\") = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetStringK_QuotedWhitespace(t *testing.T) {
+	c := conf.New("cluster_name = 'main' # comment\n")
+	err := c.SetStringK("cluster_name", "   ")
+	if err != nil {
+		t.Fatalf("SetStringK(\"cluster_name\", %q) errored with '%s', wanted no error", "   ", err)
+	}
+	want := "cluster_name = '   ' # comment\n"
+	if got := c.All(); got != want {
+		t.Errorf("SetStringK(\"cluster_name\", %q) = %q, want %q", "   ", got, want)
+	}
+	got, err := c.StringK("cluster_name")
+	if err != nil || got != "   " {
+		t.Errorf("StringK(\"cluster_name\") = %q, %v, want %q", got, err, "   ")
+	}
+}
+
+func TestSetStringK_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string // Line as written to the file
+	}{
+		{"Spaces and single quotes", `my 'special' cluster`, `cluster_name = 'my ''special'' cluster' # comment`},
+		{"Double and single quotes", `"double" and 'single'`, `cluster_name = '"double" and ''single''' # comment`},
+		{"Only a quote", `'`, `cluster_name = '''' # comment`},
+		{"Backslash", `back\slash`, `cluster_name = 'back\\slash' # comment`},
+		{"Backslash before quote", `it\'s`, `cluster_name = 'it\\''s' # comment`},
+		{"Trailing backslash", `c:\`, `cluster_name = 'c:\\' # comment`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New("cluster_name = 'main' # comment\n")
+			err := c.SetStringK("cluster_name", tt.value)
+			if err != nil {
+				t.Fatalf("SetStringK(\"cluster_name\", %q) errored with '%s', wanted no error", tt.value, err)
+			}
+			if got := readLine(t, c.All(), 1); got != tt.want {
+				t.Errorf("SetStringK(\"cluster_name\", %q) wrote %q, want %q", tt.value, got, tt.want)
+			}
+			got, err := c.StringK("cluster_name")
+			if err != nil {
+				t.Errorf("StringK(\"cluster_name\") errored with '%s', wanted no error", err)
+			} else if got != tt.value {
+				t.Errorf("StringK(\"cluster_name\") = %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestFloat64K_ScientificNotation(t *testing.T) {
+	c := conf.New("cpu_operator_cost = 1e-3\n" +
+		"cpu_tuple_cost = '2.5E-2'\n" +
+		"seq_page_cost = 1.5e+2\n")
+
+	tests := []struct {
+		key  string
+		want float64
+	}{
+		{"cpu_operator_cost", 0.001},
+		{"cpu_tuple_cost", 0.025},
+		{"seq_page_cost", 150},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := c.Float64K(tt.key)
+			if err != nil {
+				t.Errorf("Float64K(%q) errored with '%s', wanted no error", tt.key, err)
+			} else if got != tt.want {
+				t.Errorf("Float64K(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetFloat64SciK(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float64
+		prec  int
+		want  string
+	}{
+		{"Small value", 0.001, -1, "cpu_operator_cost = 1e-03 # comment\n"},
+		{"Fixed precision", 0.0025, 2, "cpu_operator_cost = 2.50e-03 # comment\n"},
+		{"Large value", 12345678, 3, "cpu_operator_cost = 1.235e+07 # comment\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New("cpu_operator_cost = 0.0025 # comment\n")
+			err := c.SetFloat64SciK("cpu_operator_cost", tt.value, tt.prec)
+			if err != nil {
+				t.Fatalf("SetFloat64SciK(%v, %d) errored with '%s', wanted no error", tt.value, tt.prec, err)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("SetFloat64SciK(%v, %d) = %q, want %q", tt.value, tt.prec, got, tt.want)
+			}
+			if got, err := c.Float64K("cpu_operator_cost"); err != nil || got == 0 {
+				t.Errorf("Float64K() = %v, %v after SetFloat64SciK, want the value read back", got, err)
+			}
+		})
+	}
+}
+
+func TestCanonicalBoolStringK(t *testing.T) {
+	c := conf.New("a = yes\nb = 'true'\nc = 1\nd = off\ne = n\nf = 'syslog'\n")
+
+	tests := []struct {
+		key     string
+		want    string
+		noerror bool
+	}{
+		{"a", "on", true},
+		{"b", "on", true},
+		{"c", "on", true},
+		{"d", "off", true},
+		{"e", "off", true},
+		{"f", "", false},
+		{"there_is_no_such_key", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := c.CanonicalBoolStringK(tt.key)
+			if err != nil && tt.noerror {
+				t.Errorf("CanonicalBoolStringK(%q) errored with '%s', wanted no error", tt.key, err)
+			} else if err == nil && !tt.noerror {
+				t.Errorf("CanonicalBoolStringK(%q) did not error, wanted error", tt.key)
+			} else if got != tt.want {
+				t.Errorf("CanonicalBoolStringK(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetRawK_BlankFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"Zero-length file", "", "port = 5432"},
+		{"Blank lines only", "\n\n\n", "port = 5432"},
+		{"Whitespace only", "  \t\r\n \n  ", "port = 5432"},
+		{"Comment only", "# comment\n\n", "# comment\n\nport = 5432"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(tt.content)
+			if err := c.SetRawK("port", "5432"); err != nil {
+				t.Fatalf("SetRawK(\"port\", \"5432\") errored with '%s', wanted no error", err)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("SetRawK(\"port\", \"5432\") = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsPgBoolK(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    bool
+		noerror bool
+	}{
+		{"on", true, true},
+		{"ON", true, true},
+		{"of", false, true},
+		{"off", false, true},
+		{"t", true, true},
+		{"tru", true, true},
+		{"f", false, true},
+		{"y", true, true},
+		{"n", false, true},
+		{"1", true, true},
+		{"0", false, true},
+		{"'yes'", true, true},
+		{"o", false, false},
+		{"fast", false, false},
+		{"northbound", false, false},
+		{"truest", false, false},
+		{"offline", false, false},
+		{"10", false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			c := conf.New("fsync = " + tt.value + "\n")
+			got, err := c.AsPgBoolK("fsync")
+			if err != nil && tt.noerror {
+				t.Errorf("AsPgBoolK(%q) errored with '%s', wanted no error", tt.value, err)
+			} else if err == nil && !tt.noerror {
+				t.Errorf("AsPgBoolK(%q) did not error, wanted error", tt.value)
+			} else if err == nil && got != tt.want {
+				t.Errorf("AsPgBoolK(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInt64K_Boundaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int64
+		noerror bool
+	}{
+		{"Documented max", "2000000000", 2000000000, true},
+		{"Above int32", "512345678901", 512345678901, true},
+		{"Max int64", "9223372036854775807", 9223372036854775807, true},
+		{"Overflowing int64", "9223372036854775808", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New("autovacuum_multixact_freeze_max_age = " + tt.value)
+			got, err := c.Int64K("autovacuum_multixact_freeze_max_age")
+			if err != nil && tt.noerror {
+				t.Errorf("Int64K() errored with '%s', wanted no error", err)
+			} else if err == nil && !tt.noerror {
+				t.Errorf("Int64K() did not error, wanted error")
+			} else if err == nil && got != tt.want {
+				t.Errorf("Int64K() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetInt64ValidatedK(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   int64
+		noerror bool
+	}{
+		{"In range", "autovacuum_multixact_freeze_max_age", 1500000000, true},
+		{"Documented max", "autovacuum_multixact_freeze_max_age", 2000000000, true},
+		{"Above documented max", "autovacuum_multixact_freeze_max_age", 2000000001, false},
+		{"Far above documented max", "autovacuum_multixact_freeze_max_age", 512345678901, false},
+		{"Below documented min", "autovacuum_multixact_freeze_max_age", 9999, false},
+		{"Unknown key", "my.custom_setting", 512345678901, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "autovacuum_multixact_freeze_max_age = 400000000\n"
+			c := conf.New(content)
+			err := c.SetInt64ValidatedK(tt.key, tt.value)
+			if err != nil && tt.noerror {
+				t.Errorf("SetInt64ValidatedK(%q, %d) errored with '%s', wanted no error", tt.key, tt.value, err)
+			} else if err == nil && !tt.noerror {
+				t.Errorf("SetInt64ValidatedK(%q, %d) did not error, wanted error", tt.key, tt.value)
+			} else if err != nil && c.All() != content {
+				t.Errorf("SetInt64ValidatedK(%q, %d) errored, but changed configuration to %q", tt.key, tt.value, c.All())
+			} else if err == nil {
+				if got, _ := c.Int64K(tt.key); got != tt.value {
+					t.Errorf("Int64K(%q) = %d after SetInt64ValidatedK, want %d", tt.key, got, tt.value)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffToDisk(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgconf")
+	if err != nil {
+		t.Fatalf("TempDir() failed: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "postgresql.conf")
+	content := "# Connections\nlisten_addresses = '*'\nport = 5432\nmax_connections = 100\n\n# Memory\nshared_buffers = 128MB\nwork_mem = 4MB\n\n# Logging\nlog_connections = on\n"
+	if err := ioutil.WriteFile(filename, []byte(content), 0600); err != nil {
+		t.Fatalf("WriteFile(%q) failed: %s", filename, err)
+	}
+	c, err := conf.Open(filename)
+	if err != nil {
+		t.Fatalf("Open(%q) failed: %s", filename, err)
+	}
+
+	if diff, err := c.DiffToDisk(); err != nil || diff != "" {
+		t.Errorf("DiffToDisk() = %q, %v before changes, want empty diff", diff, err)
+	}
+
+	c.SetRawK("shared_buffers", "256MB")
+	diff, err := c.DiffToDisk()
+	if err != nil {
+		t.Fatalf("DiffToDisk() errored with '%s', wanted no error", err)
+	}
+	want := "--- " + filename + "\n" +
+		"+++ " + filename + "\n" +
+		"@@ -4,7 +4,7 @@\n" +
+		" max_connections = 100\n" +
+		" \n" +
+		" # Memory\n" +
+		"-shared_buffers = 128MB\n" +
+		"+shared_buffers = 256MB\n" +
+		" work_mem = 4MB\n" +
+		" \n" +
+		" # Logging\n"
+	if diff != want {
+		t.Errorf("DiffToDisk() = %q, want %q", diff, want)
+	}
+
+	if _, err := conf.New(content).DiffToDisk(); err != generic.ErrNoBackingFile {
+		t.Errorf("DiffToDisk() errored with '%v' for a New() configuration, want '%s'", err, generic.ErrNoBackingFile)
+	}
+}
+
+func TestAsBoolSliceK(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []bool
+		wantErr bool
+	}{
+		{"Quoted list", "flags = 'on,off,true'", []bool{true, false, true}, false},
+		{"Spaces around elements", "flags = 'yes, no , 1'", []bool{true, false, true}, false},
+		{"Single element", "flags = off", []bool{false}, false},
+		{"Bad element", "flags = 'on,maybe,off'", nil, true},
+		{"Empty element", "flags = 'on,,off'", nil, true},
+		{"Missing key", "other = on", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := conf.New(tt.content)
+			got, err := conf.AsBoolSliceK("flags")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("AsBoolSliceK() = %v, did not error, wanted error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AsBoolSliceK() errored with '%s', wanted no error", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AsBoolSliceK() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringK_UnquotedMultiToken(t *testing.T) {
+	c := conf.New("log_line_prefix = %m [%p] %q%u@%d   # prefix\n" +
+		"application_name = 'app'\n")
+
+	got, err := c.StringK("log_line_prefix")
+	if err != nil {
+		t.Fatalf("StringK(%q) errored with '%s', wanted no error", "log_line_prefix", err)
+	}
+	if want := "%m [%p] %q%u@%d"; got != want {
+		t.Errorf("StringK(%q) = %q, want %q", "log_line_prefix", got, want)
+	}
+
+	if err := c.SetStringK("log_line_prefix", "%t "); err != nil {
+		t.Fatalf("SetStringK(%q) errored with '%s', wanted no error", "log_line_prefix", err)
+	}
+	want := "log_line_prefix = '%t '   # prefix\n" +
+		"application_name = 'app'\n"
+	if got := c.All(); got != want {
+		t.Errorf("SetStringK() changed configuration to %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeKey(t *testing.T) {
+	if got, want := conf.NormalizeKey("  Max_Connections "), "max_connections"; got != want {
+		t.Errorf("NormalizeKey() = %q, want %q", got, want)
+	}
+
+	c := conf.New("max_connections = 100\n")
+	got, err := c.IntK("  Max_Connections ")
+	if err != nil {
+		t.Fatalf("IntK() errored with '%s', wanted no error", err)
+	}
+	if got != 100 {
+		t.Errorf("IntK() = %d, want %d", got, 100)
+	}
+}
+
+func TestSetRawKDelta(t *testing.T) {
+	content := "port = 5432\nwork_mem = 4MB\n"
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  int
+	}{
+		{"Longer value", "work_mem", "'64MB'", 3},
+		{"Shorter value", "port", "1", -3},
+		{"New key", "max_connections", "100", len("max_connections = 100")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(content)
+			got, err := c.SetRawKDelta(tt.key, tt.value)
+			if err != nil {
+				t.Fatalf("SetRawKDelta(%q, %q) errored with '%s', wanted no error", tt.key, tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("SetRawKDelta(%q, %q) = %d, want %d", tt.key, tt.value, got, tt.want)
+			}
+			if c.All() != content {
+				t.Errorf("SetRawKDelta() changed configuration to %q, want it unchanged", c.All())
+			}
+		})
+	}
+
+	got, err := conf.New("port = 5432").SetRawKDelta("fsync", "off")
+	if err != nil {
+		t.Fatalf("SetRawKDelta() errored with '%s', wanted no error", err)
+	}
+	if want := len("\nfsync = off"); got != want {
+		t.Errorf("SetRawKDelta() without EOL at end = %d, want %d", got, want)
+	}
+}
+
+func TestStringK_EqualSignVariants(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"Spaces around equal sign", "search_path = public", "public"},
+		{"Whitespace only", "search_path public", "public"},
+		{"No spaces", "search_path=public", "public"},
+		{"Tabs around equal sign", "search_path\t=\tpublic", "public"},
+		{"Equal sign in value", "search_path = a=b", "a=b"},
+		{"Equal sign in value without spaces", "search_path=a=b", "a=b"},
+		{"Equal sign in value and comment", "search_path = a=b=c # comment", "a=b=c"},
+		{"Equal sign in quoted value", "search_path = 'a = b'", "a = b"},
+		{"Double equal sign", "search_path == public", "public"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(tt.content)
+			got, err := c.StringK("search_path")
+			if err != nil {
+				t.Fatalf("StringK(%q) errored with '%s', wanted no error", "search_path", err)
+			}
+			if got != tt.want {
+				t.Errorf("StringK(%q) = %q, want %q", "search_path", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetRawK_EqualSignInValue(t *testing.T) {
+	c := conf.New("search_path = a=b # comment\n")
+	if err := c.SetRawK("search_path", "'x=y'"); err != nil {
+		t.Fatalf("SetRawK() errored with '%s', wanted no error", err)
+	}
+	if got, want := c.All(), "search_path = 'x=y' # comment\n"; got != want {
+		t.Errorf("SetRawK() changed configuration to %q, want %q", got, want)
+	}
+}
+
+func TestSetRawKMaxLen(t *testing.T) {
+	content := "application_name = 'app' # name\n"
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"Fits", "application_name", "'billing'", "application_name = 'billing' # name\n", false},
+		{"Exactly at limit", "application_name", "'billing-1234'", "application_name = 'billing-1234' # name\n", false},
+		{"Too long", "application_name", "'billing-12345'", content, true},
+		{"New key too long", "cluster_name", "'a-very-long-cluster-name'", content, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(content)
+			err := c.SetRawKMaxLen(tt.key, tt.value, 40)
+			if tt.wantErr && err == nil {
+				t.Errorf("SetRawKMaxLen(%q, %q) did not error, wanted error", tt.key, tt.value)
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("SetRawKMaxLen(%q, %q) errored with '%s', wanted no error", tt.key, tt.value, err)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("SetRawKMaxLen() changed configuration to %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoolStyleK(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"on", conf.BoolStyleOnOff, false},
+		{"'OFF'", conf.BoolStyleOnOff, false},
+		{"true", conf.BoolStyleTrueFalse, false},
+		{"f", conf.BoolStyleTrueFalse, false},
+		{"yes", conf.BoolStyleYesNo, false},
+		{"no", conf.BoolStyleYesNo, false},
+		{"1", conf.BoolStyleOneZero, false},
+		{"0", conf.BoolStyleOneZero, false},
+		{"maybe", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := conf.New("fsync = " + tt.value).BoolStyleK("fsync")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("BoolStyleK() = %q, did not error, wanted error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BoolStyleK() errored with '%s', wanted no error", err)
+			}
+			if got != tt.want {
+				t.Errorf("BoolStyleK() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsNonEmptyStringK(t *testing.T) {
+	c := conf.New("ssl_ca_file = ''\nssl_cert_file = 'server.crt'\n")
+	tests := []struct {
+		key         string
+		want        string
+		wantPresent bool
+		wantErr     bool
+	}{
+		{"ssl_ca_file", "", false, false},
+		{"ssl_cert_file", "server.crt", true, false},
+		{"ssl_key_file", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, present, err := c.AsNonEmptyStringK(tt.key)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("AsNonEmptyStringK(%q) errored with '%v', wanted error: %t", tt.key, err, tt.wantErr)
+			}
+			if got != tt.want || present != tt.wantPresent {
+				t.Errorf("AsNonEmptyStringK(%q) = %q, %t, want %q, %t", tt.key, got, present, tt.want, tt.wantPresent)
+			}
+		})
+	}
+}
+
+func TestFirstPresentK(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr error
+	}{
+		{"Only old name", "checkpoint_segments = 32\n", "32", nil},
+		{"Both names", "checkpoint_segments = 32\nmax_wal_size = '2GB'\n", "2GB", nil},
+		{"Neither name", "port = 5432\n", "", generic.ErrKeyNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := conf.New(tt.content).FirstPresentK("max_wal_size", "checkpoint_segments")
+			if err != tt.wantErr {
+				t.Fatalf("FirstPresentK() errored with '%v', want '%v'", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("FirstPresentK() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRawBytesK(t *testing.T) {
+	c := openTestFile(t, "postgresql.conf")
+	for _, key := range []string{"listen_addresses", "port", "log_destination", "shared_buffers"} {
+		got, err := c.RawBytesK(key)
+		if err != nil {
+			t.Fatalf("RawBytesK(%q) errored with '%s', wanted no error", key, err)
+		}
+		want, _ := c.RawK(key)
+		if string(got) != want {
+			t.Errorf("RawBytesK(%q) = %q, want %q", key, got, want)
+		}
+	}
+	if _, err := c.RawBytesK("no_such_key"); err != generic.ErrKeyNotFound {
+		t.Errorf("RawBytesK() errored with '%v', want '%s'", err, generic.ErrKeyNotFound)
+	}
+}
+
+func TestSetStringK_RoundTripQuotes(t *testing.T) {
+	values := []string{
+		`it's a "quote"`,
+		`'"`,
+		`""''`,
+		`back\slash`,
+		`c:\`,
+		`a\'b\"c`,
+	}
+	for _, value := range values {
+		t.Run(value, func(t *testing.T) {
+			c := conf.New("key = 'value'\n")
+			if err := c.SetStringK("key", value); err != nil {
+				t.Fatalf("SetStringK(%q) errored with '%s', wanted no error", value, err)
+			}
+			got, err := c.StringK("key")
+			if err != nil {
+				t.Fatalf("StringK() errored with '%s', wanted no error", err)
+			}
+			if got != value {
+				t.Errorf("StringK() = %q after SetStringK(%q) wrote %q, want %q", got, value, c.All(), value)
+			}
+		})
+	}
+}
+
+func BenchmarkRawBytesK(b *testing.B) {
+	c := conf.New(readTestFile(b, "postgresql-default.conf"))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.RawBytesK("shared_buffers")
+	}
+}
+
+func BenchmarkRawK(b *testing.B) {
+	c := conf.New(readTestFile(b, "postgresql-default.conf"))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.RawK("shared_buffers")
+	}
+}
+
+func TestAppendFormatted(t *testing.T) {
+	tests := []struct {
+		name     string
+		conf     string
+		key      string
+		value    string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{"Tab-aligned", "port = 5432\n", "work_mem", "64MB", "%k\t\t= %v\t# tuned", "port = 5432\nwork_mem\t\t= 64MB\t# tuned", false},
+		{"No delimiter", "", "port", "5433", "%k=%v", "port=5433", false},
+		{"Missing value placeholder", "port = 5432\n", "work_mem", "64MB", "%k = 64MB", "port = 5432\n", true},
+		{"Repeated placeholder", "port = 5432\n", "work_mem", "64MB", "%k = %v # %k", "port = 5432\n", true},
+		{"Value before key", "port = 5432\n", "work_mem", "64MB", "%v = %k", "port = 5432\n", true},
+		{"Multiple lines", "port = 5432\n", "work_mem", "64MB", "%k =\n%v", "port = 5432\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(tt.conf)
+			_, err := c.AppendFormatted(tt.key, tt.value, tt.template)
+			if tt.wantErr && err == nil {
+				t.Errorf("AppendFormatted() did not error, wanted error")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("AppendFormatted() errored with '%s', wanted no error", err)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("AppendFormatted() changed configuration to %q, want %q", got, tt.want)
+			}
+			if !tt.wantErr {
+				if got, _ := c.RawK(tt.key); got != tt.value {
+					t.Errorf("RawK(%q) = %q, want %q", tt.key, got, tt.value)
+				}
+			}
+		})
+	}
+}
+
+func TestBoolKOrDefault(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		want    bool
+		wantErr bool
+	}{
+		{"Set in file", "fsync", false, false},
+		{"Default from registry", "wal_log_hints", false, false},
+		{"Default on from registry", "full_page_writes", true, false},
+		{"Not a boolean default", "port", false, true},
+		{"Unknown key", "no_such_key", false, true},
+	}
+	c := conf.New("fsync = off\n")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.BoolKOrDefault(tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("BoolKOrDefault(%q) did not error, wanted error", tt.key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BoolKOrDefault(%q) errored with '%s', wanted no error", tt.key, err)
+			}
+			if got != tt.want {
+				t.Errorf("BoolKOrDefault(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrParseFailed(t *testing.T) {
+	c := conf.New("port = abc\nwork_mem = 4MB\ncheckpoint_completion_target = half\nfsync = maybe\nmax_connections = 100\nshared_buffers = lots\n")
+	accessors := []struct {
+		name string
+		get  func(key string) error
+	}{
+		{"IntK", func(key string) error { _, err := c.IntK(key); return err }},
+		{"Int64K", func(key string) error { _, err := c.Int64K(key); return err }},
+		{"Float64K", func(key string) error { _, err := c.Float64K(key); return err }},
+		{"BoolK", func(key string) error { _, err := c.BoolK(key); return err }},
+		{"AsPgBoolK", func(key string) error { _, err := c.AsPgBoolK(key); return err }},
+		{"AsBoolSliceK", func(key string) error { _, err := c.AsBoolSliceK(key); return err }},
+		{"AsBytesK", func(key string) error { _, err := c.AsBytesK(key); return err }},
+		{"AsBytesLenientK", func(key string) error { _, err := c.AsBytesLenientK(key); return err }},
+	}
+	tests := []struct {
+		key        string
+		wantParse  []bool // Whether each accessor should fail with ErrParseFailed
+		wantExists bool
+	}{
+		{"port", []bool{true, true, true, true, true, true, true, true}, true},
+		{"checkpoint_completion_target", []bool{true, true, true, true, true, true, true, true}, true},
+		{"fsync", []bool{true, true, true, true, true, true, true, true}, true},
+		{"max_connections", []bool{false, false, false, true, true, true, false, false}, true},
+		{"shared_buffers", []bool{true, true, true, true, true, true, true, true}, true},
+		{"no_such_key", []bool{false, false, false, false, false, false, false, false}, false},
+	}
+	for _, tt := range tests {
+		for i, a := range accessors {
+			err := a.get(tt.key)
+			if got := errors.Is(err, conf.ErrParseFailed); got != tt.wantParse[i] {
+				t.Errorf("errors.Is(%s(%q), ErrParseFailed) = %v, want %v (error '%v')", a.name, tt.key, got, tt.wantParse[i], err)
+			}
+			if got := errors.Is(err, generic.ErrKeyNotFound); got == tt.wantExists {
+				t.Errorf("errors.Is(%s(%q), ErrKeyNotFound) = %v, want %v", a.name, tt.key, got, !tt.wantExists)
+			}
+		}
+	}
+
+	_, err := c.IntK("port")
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Errorf("IntK(%q) errored with '%s', want a wrapped *strconv.NumError", "port", err)
+	}
+	var parseErr *conf.ParseError
+	if !errors.As(err, &parseErr) || parseErr.Key != "port" || parseErr.Value != "abc" {
+		t.Errorf("IntK(%q) errored with '%s', want a *ParseError for key %q and value %q", "port", err, "port", "abc")
+	}
+}
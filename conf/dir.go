@@ -0,0 +1,171 @@
+package conf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/quasoft/pgconf/generic"
+)
+
+// OpenDir reads every *.conf file in the directory in lexical order (as PostgreSQL does for
+// include_dir) and concatenates them into a single configuration, so that settings in later files
+// override the ones in earlier files. Other files, as well as hidden files, are ignored.
+// The file each line was read from can be retrieved with LineSource.
+func OpenDir(dir string) (*Conf, error) {
+	filenames, err := filepath.Glob(filepath.Join(dir, "*.conf"))
+	if err != nil {
+		return nil, fmt.Errorf("could not list files in %s: %s", dir, err)
+	}
+
+	var content strings.Builder
+	var sources []string
+	lineSources := []string{} // Not nil, so that lines are tagged even if all files are empty
+	for _, filename := range filenames {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return nil, fmt.Errorf("could not read file %s: %s", filename, err)
+		}
+		if info.IsDir() || strings.HasPrefix(filepath.Base(filename), ".") {
+			continue
+		}
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("could not read file %s: %s", filename, err)
+		}
+		text := string(data)
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n" // Make sure the next file starts on a new line
+		}
+		content.WriteString(text)
+		sources = append(sources, filename)
+		lineSources = appendLineSources(lineSources, filename, text)
+	}
+
+	c := New(content.String())
+	c.sources = sources
+	if err := c.SetLineTags(lineSources); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// appendLineSources appends the name of the file once for every line of the text read from it.
+// The text must end with an EOL character.
+func appendLineSources(lineSources []string, filename, text string) []string {
+	for i := strings.Count(text, "\n"); i > 0; i-- {
+		lineSources = append(lineSources, filename)
+	}
+	return lineSources
+}
+
+// lineSources returns the name of the source file of every line of the configuration, as
+// recorded when the line was read. Changed lines keep the file of the line they replaced, while
+// inserted lines are attributed to the file of the preceding line (or to the first file, if there
+// is none), except for lines appended after the last line read, which are attributed to the file
+// set with SetAppendTarget, if any.
+// Returns nil if the configuration was not read with OpenDir or OpenWithIncludes.
+func (c *Conf) lineSources() []string {
+	tags := c.LineTags()
+	if len(c.sources) == 0 || tags == nil {
+		return nil
+	}
+	last := -1 // Index of the last line that was read from a file
+	for i, tag := range tags {
+		if tag != "" {
+			last = i
+		}
+	}
+	prev := c.sources[0]
+	for i, tag := range tags {
+		if tag != "" {
+			prev = tag
+		} else if i > last && c.appendTarget != "" {
+			prev = c.appendTarget
+		}
+		tags[i] = prev
+	}
+	return tags
+}
+
+// SetAppendTarget sets the file, to which SaveSources writes lines appended after the last line
+// read with OpenDir (eg. new settings added with SetRawK). By default such lines are written to
+// the last file read, which has the highest precedence.
+func (c *Conf) SetAppendTarget(filename string) {
+	c.appendTarget = filename
+}
+
+// SaveSources writes every line of a configuration read with OpenDir or OpenWithIncludes back to
+// the file it was read from (see LineSource), so that a change to a setting read from a file
+// updates exactly that file.
+// Lines appended at the end are written to the file set with SetAppendTarget, which is created if
+// it does not exist. Every file is written with an EOL character at the end, while files whose
+// content did not change are not written at all. Files keep their permissions, while new files
+// are created with 0600.
+// Afterwards, every line is attributed to the file it was written to (see LineSource).
+// Returns generic.ErrNoBackingFile if the configuration was not read with OpenDir or
+// OpenWithIncludes.
+func (c *Conf) SaveSources() error {
+	sources := c.lineSources()
+	if sources == nil {
+		return generic.ErrNoBackingFile
+	}
+
+	var filenames []string
+	contents := make(map[string]string)
+	for _, filename := range c.sources {
+		if _, ok := contents[filename]; !ok {
+			filenames = append(filenames, filename)
+			contents[filename] = ""
+		}
+	}
+
+	all := c.All()
+	for i, line := range c.Lines() {
+		filename := sources[i]
+		if _, ok := contents[filename]; !ok {
+			filenames = append(filenames, filename)
+		}
+		text := all[line.Start:line.End]
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		contents[filename] += text
+	}
+
+	for _, filename := range filenames {
+		perm := os.FileMode(0600)
+		if info, err := os.Stat(filename); err == nil {
+			perm = info.Mode().Perm()
+		}
+		if old, err := ioutil.ReadFile(filename); err != nil || string(old) != contents[filename] {
+			if err := ioutil.WriteFile(filename, []byte(contents[filename]), perm); err != nil {
+				return fmt.Errorf("could not write file %s: %s", filename, err)
+			}
+		}
+	}
+	// Record the files lines were written to, so that appended lines stay with the file they
+	// were written to, even if the append target is changed
+	c.sources = filenames
+	return c.SetLineTags(sources)
+}
+
+// LineSource returns the name of the file the line with the given 1-based number was read from,
+// when the configuration was read with OpenDir or OpenWithIncludes. Lines added after loading are
+// attributed to the file of the preceding line. Returns generic.ErrNoBackingFile if the
+// configuration was not read with OpenDir or OpenWithIncludes.
+func (c *Conf) LineSource(number int) (string, error) {
+	if _, err := c.LineAt(number); err != nil {
+		return "", err
+	}
+	sources := c.lineSources()
+	if sources == nil {
+		return "", generic.ErrNoBackingFile
+	}
+	if number > len(sources) {
+		return "", nil
+	}
+	return sources[number-1], nil
+}
@@ -0,0 +1,158 @@
+package conf_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quasoft/pgconf/conf"
+	"github.com/quasoft/pgconf/generic"
+)
+
+// writeTestDir creates a temporary directory with the given files.
+func writeTestDir(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "pgconf")
+	if err != nil {
+		t.Fatalf("TempDir() failed: %s", err)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("WriteFile(%q) failed: %s", name, err)
+		}
+	}
+	return dir
+}
+
+func TestOpenDir(t *testing.T) {
+	dir := writeTestDir(t, map[string]string{
+		"20-tuning.conf":  "work_mem = 64MB\nshared_buffers = 1GB",
+		"10-base.conf":    "# Base settings\nwork_mem = 4MB\nport = 5432\n",
+		"30-notes.txt":    "work_mem = 1MB\n",
+		".99-hidden.conf": "work_mem = 2MB\n",
+	})
+	defer os.RemoveAll(dir)
+
+	c, err := conf.OpenDir(dir)
+	if err != nil {
+		t.Fatalf("OpenDir() errored with '%s', wanted no error", err)
+	}
+
+	if got, err := c.StringK("work_mem"); err != nil || got != "64MB" {
+		t.Errorf("StringK(%q) = %q, %v, want %q from the later file", "work_mem", got, err, "64MB")
+	}
+	if got, err := c.IntK("port"); err != nil || got != 5432 {
+		t.Errorf("IntK(%q) = %d, %v, want %d", "port", got, err, 5432)
+	}
+
+	wantSources := []string{"10-base.conf", "10-base.conf", "10-base.conf", "20-tuning.conf", "20-tuning.conf"}
+	for i, want := range wantSources {
+		got, err := c.LineSource(i + 1)
+		if err != nil {
+			t.Fatalf("LineSource(%d) errored with '%s', wanted no error", i+1, err)
+		}
+		if got != filepath.Join(dir, want) {
+			t.Errorf("LineSource(%d) = %q, want %q", i+1, got, filepath.Join(dir, want))
+		}
+	}
+	// Changed lines keep the source of the line they replaced, even at the start of a file
+	c.SetRawK("work_mem", "128MB")
+	if got, _ := c.LineSource(4); got != filepath.Join(dir, "20-tuning.conf") {
+		t.Errorf("LineSource(4) after change = %q, want %q", got, filepath.Join(dir, "20-tuning.conf"))
+	}
+	if _, err := c.LineSource(len(wantSources) + 1); err == nil {
+		t.Errorf("LineSource(%d) did not error, wanted error", len(wantSources)+1)
+	}
+	if _, err := conf.New("port = 5432\n").LineSource(1); err != generic.ErrNoBackingFile {
+		t.Errorf("LineSource(1) errored with '%v' for a New() configuration, want '%s'", err, generic.ErrNoBackingFile)
+	}
+}
+
+func TestLineSource_IdenticalLines(t *testing.T) {
+	dir := writeTestDir(t, map[string]string{
+		"10-base.conf":   "port = 5432\n",
+		"20-tuning.conf": "port = 5432\n",
+	})
+	defer os.RemoveAll(dir)
+
+	c, err := conf.OpenDir(dir)
+	if err != nil {
+		t.Fatalf("OpenDir() errored with '%s', wanted no error", err)
+	}
+	// The remaining line is the one read from the second file, though it equals the removed one
+	if err := c.RemoveLine(c.Lines()[0]); err != nil {
+		t.Fatalf("RemoveLine() errored with '%s', wanted no error", err)
+	}
+	got, err := c.LineSource(1)
+	if err != nil {
+		t.Fatalf("LineSource(1) errored with '%s', wanted no error", err)
+	}
+	if want := filepath.Join(dir, "20-tuning.conf"); got != want {
+		t.Errorf("LineSource(1) after removing line 1 = %q, want %q", got, want)
+	}
+}
+
+func TestSaveSources(t *testing.T) {
+	base := "# Base settings\nwork_mem = 4MB\nport = 5432\n"
+	dir := writeTestDir(t, map[string]string{
+		"10-base.conf":   base,
+		"20-tuning.conf": "shared_buffers = 1GB\n",
+	})
+	defer os.RemoveAll(dir)
+
+	c, err := conf.OpenDir(dir)
+	if err != nil {
+		t.Fatalf("OpenDir() errored with '%s', wanted no error", err)
+	}
+	baseFile := filepath.Join(dir, "10-base.conf")
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(baseFile, past, past); err != nil {
+		t.Fatalf("Chtimes(%q) failed: %s", baseFile, err)
+	}
+	c.SetRawK("shared_buffers", "2GB")
+	c.SetRawK("max_connections", "200")
+	c.SetAppendTarget(filepath.Join(dir, "90-auto.conf"))
+	if err := c.SaveSources(); err != nil {
+		t.Fatalf("SaveSources() errored with '%s', wanted no error", err)
+	}
+
+	want := map[string]string{
+		"10-base.conf":   base,
+		"20-tuning.conf": "shared_buffers = 2GB\n",
+		"90-auto.conf":   "max_connections = 200\n",
+	}
+	for name, content := range want {
+		got, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("ReadFile(%q) failed: %s", name, err)
+		}
+		if string(got) != content {
+			t.Errorf("SaveSources() wrote %q to %s, want %q", got, name, content)
+		}
+	}
+
+	if info, err := os.Stat(baseFile); err != nil || !info.ModTime().Equal(past) {
+		t.Errorf("SaveSources() rewrote unchanged file 10-base.conf")
+	}
+
+	// Appended lines keep the file they were saved to, even if the append target is changed
+	c.SetAppendTarget("")
+	if got, _ := c.LineSource(5); got != filepath.Join(dir, "90-auto.conf") {
+		t.Errorf("LineSource(5) after SaveSources() = %q, want %q", got, filepath.Join(dir, "90-auto.conf"))
+	}
+
+	// A second save after more changes still routes lines to their files
+	c.SetRawK("port", "5433")
+	if err := c.SaveSources(); err != nil {
+		t.Fatalf("SaveSources() errored with '%s', wanted no error", err)
+	}
+	got, _ := ioutil.ReadFile(filepath.Join(dir, "10-base.conf"))
+	if want := "# Base settings\nwork_mem = 4MB\nport = 5433\n"; string(got) != want {
+		t.Errorf("second SaveSources() wrote %q to 10-base.conf, want %q", got, want)
+	}
+
+	if err := conf.New("port = 5432").SaveSources(); err == nil {
+		t.Errorf("SaveSources() without sources did not error, wanted error")
+	}
+}
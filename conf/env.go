@@ -0,0 +1,43 @@
+package conf
+
+import (
+	"fmt"
+	"os"
+)
+
+// SetExpandEnv enables or disables expansion of environment variables when reading values.
+// When enabled, references to variables in the form ${VAR} or $VAR in dequoted values returned by
+// StringK, IntK, Int64K, Float64K, BoolK and friends are replaced with the value of the variable
+// from the process environment. Unset variables expand to an empty string, unless
+// SetErrorOnUnsetEnv is enabled. Raw values are never expanded.
+// Expansion is disabled by default.
+func (c *Conf) SetExpandEnv(expand bool) {
+	c.expandEnv = expand
+}
+
+// SetErrorOnUnsetEnv makes reading of values that reference unset environment variables fail
+// with an error, instead of expanding the variables to an empty string. Has effect only if
+// expansion of environment variables is enabled (see SetExpandEnv).
+func (c *Conf) SetErrorOnUnsetEnv(errorOnUnset bool) {
+	c.strictEnv = errorOnUnset
+}
+
+// expandEnvK expands references to environment variables in the dequoted value of the key,
+// if expansion is enabled.
+func (c *Conf) expandEnvK(key string, value string) (string, error) {
+	if !c.expandEnv {
+		return value, nil
+	}
+	var unset string
+	expanded := os.Expand(value, func(name string) string {
+		v, ok := os.LookupEnv(name)
+		if !ok && unset == "" {
+			unset = name
+		}
+		return v
+	})
+	if c.strictEnv && unset != "" {
+		return "", fmt.Errorf("environment variable %s referenced by key %s is not set", unset, key)
+	}
+	return expanded, nil
+}
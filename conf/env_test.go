@@ -0,0 +1,57 @@
+package conf_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestSetExpandEnv(t *testing.T) {
+	os.Setenv("PGCONF_TEST_PORT", "6000")
+	defer os.Unsetenv("PGCONF_TEST_PORT")
+	os.Setenv("PGCONF_TEST_HOST", "db1")
+	defer os.Unsetenv("PGCONF_TEST_HOST")
+	os.Unsetenv("PGCONF_TEST_UNSET")
+
+	c := conf.New("port = '${PGCONF_TEST_PORT}'\n" +
+		"listen_addresses = '$PGCONF_TEST_HOST,localhost'\n" +
+		"cluster_name = 'main${PGCONF_TEST_UNSET}'\n")
+
+	if got, err := c.StringK("port"); err != nil || got != "${PGCONF_TEST_PORT}" {
+		t.Errorf("StringK(%q) = %q, %v with expansion disabled, want %q", "port", got, err, "${PGCONF_TEST_PORT}")
+	}
+
+	c.SetExpandEnv(true)
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"port", "6000"},
+		{"listen_addresses", "db1,localhost"},
+		{"cluster_name", "main"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := c.StringK(tt.key)
+			if err != nil {
+				t.Fatalf("StringK(%q) errored with '%s', wanted no error", tt.key, err)
+			}
+			if got != tt.want {
+				t.Errorf("StringK(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	if got, err := c.IntK("port"); err != nil || got != 6000 {
+		t.Errorf("IntK(%q) = %d, %v, want %d", "port", got, err, 6000)
+	}
+	if got, err := c.RawK("port"); err != nil || got != "'${PGCONF_TEST_PORT}'" {
+		t.Errorf("RawK(%q) = %q, %v, want raw value unexpanded", "port", got, err)
+	}
+
+	c.SetErrorOnUnsetEnv(true)
+	if _, err := c.StringK("cluster_name"); err == nil {
+		t.Errorf("StringK(%q) with unset variable did not error, wanted error", "cluster_name")
+	}
+}
@@ -0,0 +1,41 @@
+package conf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultGroupDelims are the characters that enclose grouped values when grouping is enabled.
+const defaultGroupDelims = "()"
+
+// SetGroupParsing enables or disables parsing of values enclosed in parentheses, like (1, 2, 3),
+// as a single group, even if they contain whitespace or equal signs. Grouping is disabled by default.
+// Use SetParams with a custom Params.GroupDelims to group values with another pair of characters.
+func (c *Conf) SetGroupParsing(enable bool) {
+	params := c.Params()
+	params.GroupDelims = ""
+	if enable {
+		params.GroupDelims = defaultGroupDelims
+	}
+	c.SetParams(params)
+}
+
+// AsGroupK retrieves the content of a grouped value of the key (eg. a, b for the value (a, b)),
+// without the enclosing characters and surrounding whitespace. Groups are enclosed in the
+// characters of Params.GroupDelims, or in parentheses if grouping is not enabled.
+// Returns an error if the value is not a group.
+func (c *Conf) AsGroupK(key string) (string, error) {
+	value, err := c.RawK(key)
+	if err != nil {
+		return "", err
+	}
+	delims := []rune(c.Params().GroupDelims)
+	if len(delims) != 2 {
+		delims = []rune(defaultGroupDelims)
+	}
+	open, close := string(delims[0]), string(delims[1])
+	if len(value) < len(open)+len(close) || !strings.HasPrefix(value, open) || !strings.HasSuffix(value, close) {
+		return "", fmt.Errorf("value of key %s is not a group enclosed in %s%s", key, open, close)
+	}
+	return strings.TrimSpace(value[len(open) : len(value)-len(close)]), nil
+}
@@ -0,0 +1,43 @@
+package conf_test
+
+import (
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestAsGroupK(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{"Group", "ext.list = (a, b)", "a, b", false},
+		{"Group with equal signs and comment", "ext.list = (x = 1, y = 2) # comment", "x = 1, y = 2", false},
+		{"Nested group", "ext.list = ((1, 2), (3, 4))", "(1, 2), (3, 4)", false},
+		{"Quoted parenthesis", "ext.list = ('a)', b)", "'a)', b", false},
+		{"Empty group", "ext.list = ( )", "", false},
+		{"Not a group", "ext.list = 'a, b'", "", true},
+		{"Unclosed group", "ext.list = (a, b", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(tt.content)
+			c.SetGroupParsing(true)
+			got, err := c.AsGroupK("ext.list")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("AsGroupK() = %q, did not error, wanted error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AsGroupK() errored with '%s', wanted no error", err)
+			}
+			if got != tt.want {
+				t.Errorf("AsGroupK() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
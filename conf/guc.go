@@ -0,0 +1,142 @@
+package conf
+
+// GUCType is the data type of a configuration parameter (GUC), as reported by the
+// vartype column of the pg_settings view.
+type GUCType int
+
+// Constants for GUC data types
+const (
+	BoolGUC GUCType = iota
+	IntGUC
+	RealGUC
+	StringGUC
+	EnumGUC
+)
+
+// Constants for GUC contexts, which determine when changes to a parameter take effect
+// (see the context column of the pg_settings view).
+const (
+	ContextPostmaster = "postmaster" // Requires a server restart
+	ContextSighup     = "sighup"     // Applied on reload
+	ContextSuperuser  = "superuser"  // Applied on reload, can also be set by superusers per session
+	ContextUser       = "user"       // Applied on reload, can also be set by any user per session
+)
+
+// GUC describes a known PostgreSQL configuration parameter.
+type GUC struct {
+	Name        string  // Name of the parameter
+	Category    string  // Logical group of the parameter (as in pg_settings.category)
+	Description string  // Short description of the parameter (as in pg_settings.short_desc)
+	Type        GUCType // Data type of the parameter
+	Context     string  // Context required to change the parameter (eg. ContextPostmaster)
+	Unit        string  // Implicit unit of integer values (eg. kB, 8kB, MB, ms, s), if any
+	Min         float64 // Minimum allowed value for integer and real parameters
+	Max         float64 // Maximum allowed value for integer and real parameters
+	MaxLength   int     // Maximum length of string values, or 0 if unlimited
+	Default     string  // Default value as it appears in the sample postgresql.conf
+}
+
+// nameDataLen is the maximum length of identifiers (NAMEDATALEN - 1) in a default PostgreSQL build.
+const nameDataLen = 63
+
+const maxInt32 = 2147483647
+
+// gucs is a registry of commonly used parameters. It is not meant to be a complete list
+// of all parameters supported by PostgreSQL.
+var gucs = []GUC{
+	// File Locations
+	{Name: "data_directory", Category: "File Locations", Description: "Sets the server's data directory.", Type: StringGUC, Context: ContextPostmaster},
+	{Name: "hba_file", Category: "File Locations", Description: `Sets the server's "hba" configuration file.`, Type: StringGUC, Context: ContextPostmaster},
+
+	// Connections and Authentication
+	{Name: "listen_addresses", Category: "Connections and Authentication / Connection Settings", Description: "Sets the host name or IP address(es) to listen to.", Type: StringGUC, Context: ContextPostmaster, Default: "localhost"},
+	{Name: "port", Category: "Connections and Authentication / Connection Settings", Description: "Sets the TCP port the server listens on.", Type: IntGUC, Context: ContextPostmaster, Min: 1, Max: 65535, Default: "5432"},
+	{Name: "max_connections", Category: "Connections and Authentication / Connection Settings", Description: "Sets the maximum number of concurrent connections.", Type: IntGUC, Context: ContextPostmaster, Min: 1, Max: 262143, Default: "100"},
+	{Name: "reserved_connections", Category: "Connections and Authentication / Connection Settings", Description: "Sets the number of connection slots reserved for roles with privileges of pg_use_reserved_connections.", Type: IntGUC, Context: ContextPostmaster, Min: 0, Max: 262143, Default: "0"},
+	{Name: "superuser_reserved_connections", Category: "Connections and Authentication / Connection Settings", Description: "Sets the number of connection slots reserved for superusers.", Type: IntGUC, Context: ContextPostmaster, Min: 0, Max: 262143, Default: "3"},
+	{Name: "unix_socket_directories", Category: "Connections and Authentication / Connection Settings", Description: "Sets the directories where Unix-domain sockets will be created.", Type: StringGUC, Context: ContextPostmaster, Default: "/tmp"},
+	{Name: "bonjour", Category: "Connections and Authentication / Connection Settings", Description: "Enables advertising the server via Bonjour.", Type: BoolGUC, Context: ContextPostmaster, Default: "off"},
+	{Name: "password_encryption", Category: "Connections and Authentication / Authentication", Description: "Chooses the algorithm for encrypting passwords.", Type: EnumGUC, Context: ContextUser, Default: "scram-sha-256"},
+	{Name: "db_user_namespace", Category: "Connections and Authentication / Authentication", Description: "Enables per-database user names.", Type: BoolGUC, Context: ContextSighup, Default: "off"},
+	{Name: "ssl", Category: "Connections and Authentication / SSL", Description: "Enables SSL connections.", Type: BoolGUC, Context: ContextSighup, Default: "off"},
+	{Name: "ssl_ca_file", Category: "Connections and Authentication / SSL", Description: "Location of the SSL certificate authority file.", Type: StringGUC, Context: ContextSighup},
+	{Name: "ssl_cert_file", Category: "Connections and Authentication / SSL", Description: "Location of the SSL server certificate file.", Type: StringGUC, Context: ContextSighup, Default: "server.crt"},
+	{Name: "ssl_key_file", Category: "Connections and Authentication / SSL", Description: "Location of the SSL server private key file.", Type: StringGUC, Context: ContextSighup, Default: "server.key"},
+
+	// Resource Usage
+	{Name: "shared_buffers", Category: "Resource Usage / Memory", Description: "Sets the number of shared memory buffers used by the server.", Type: IntGUC, Context: ContextPostmaster, Unit: "8kB", Min: 16, Max: 1073741823, Default: "128MB"},
+	{Name: "huge_pages", Category: "Resource Usage / Memory", Description: "Use of huge pages on Linux or Windows.", Type: EnumGUC, Context: ContextPostmaster, Default: "try"},
+	{Name: "temp_buffers", Category: "Resource Usage / Memory", Description: "Sets the maximum number of temporary buffers used by each session.", Type: IntGUC, Context: ContextUser, Unit: "8kB", Min: 100, Max: 1073741823, Default: "8MB"},
+	{Name: "work_mem", Category: "Resource Usage / Memory", Description: "Sets the maximum memory to be used for query workspaces.", Type: IntGUC, Context: ContextUser, Unit: "kB", Min: 64, Max: maxInt32, Default: "4MB"},
+	{Name: "maintenance_work_mem", Category: "Resource Usage / Memory", Description: "Sets the maximum memory to be used for maintenance operations.", Type: IntGUC, Context: ContextUser, Unit: "kB", Min: 1024, Max: maxInt32, Default: "64MB"},
+	{Name: "effective_io_concurrency", Category: "Resource Usage / Asynchronous Behavior", Description: "Number of simultaneous requests that can be handled efficiently by the disk subsystem.", Type: IntGUC, Context: ContextUser, Min: 0, Max: 1000, Default: "1"},
+	{Name: "max_worker_processes", Category: "Resource Usage / Asynchronous Behavior", Description: "Maximum number of concurrent worker processes.", Type: IntGUC, Context: ContextPostmaster, Min: 0, Max: 262143, Default: "8"},
+	{Name: "max_parallel_workers_per_gather", Category: "Resource Usage / Asynchronous Behavior", Description: "Sets the maximum number of parallel processes per executor node.", Type: IntGUC, Context: ContextUser, Min: 0, Max: 1024, Default: "2"},
+	{Name: "max_parallel_workers", Category: "Resource Usage / Asynchronous Behavior", Description: "Sets the maximum number of parallel workers that can be active at one time.", Type: IntGUC, Context: ContextUser, Min: 0, Max: 1024, Default: "8"},
+
+	// Write-Ahead Log
+	{Name: "wal_level", Category: "Write-Ahead Log / Settings", Description: "Sets the level of information written to the WAL.", Type: EnumGUC, Context: ContextPostmaster, Default: "replica"},
+	{Name: "fsync", Category: "Write-Ahead Log / Settings", Description: "Forces synchronization of updates to disk.", Type: BoolGUC, Context: ContextSighup, Default: "on"},
+	{Name: "synchronous_commit", Category: "Write-Ahead Log / Settings", Description: "Sets the current transaction's synchronization level.", Type: EnumGUC, Context: ContextUser, Default: "on"},
+	{Name: "full_page_writes", Category: "Write-Ahead Log / Settings", Description: "Writes full pages to WAL when first modified after a checkpoint.", Type: BoolGUC, Context: ContextSighup, Default: "on"},
+	{Name: "wal_log_hints", Category: "Write-Ahead Log / Settings", Description: "Writes full pages to WAL when first modified after a checkpoint, even for a non-critical modification.", Type: BoolGUC, Context: ContextPostmaster, Default: "off"},
+	{Name: "wal_compression", Category: "Write-Ahead Log / Settings", Description: "Compresses full-page writes written in WAL file with specified method.", Type: EnumGUC, Context: ContextSuperuser, Default: "off"},
+	{Name: "checkpoint_timeout", Category: "Write-Ahead Log / Checkpoints", Description: "Sets the maximum time between automatic WAL checkpoints.", Type: IntGUC, Context: ContextSighup, Unit: "s", Min: 30, Max: 86400, Default: "5min"},
+	{Name: "checkpoint_completion_target", Category: "Write-Ahead Log / Checkpoints", Description: "Time spent flushing dirty buffers during checkpoint, as fraction of checkpoint interval.", Type: RealGUC, Context: ContextSighup, Min: 0, Max: 1, Default: "0.9"},
+	{Name: "max_wal_size", Category: "Write-Ahead Log / Checkpoints", Description: "Sets the WAL size that triggers a checkpoint.", Type: IntGUC, Context: ContextSighup, Unit: "MB", Min: 2, Max: maxInt32, Default: "1GB"},
+	{Name: "min_wal_size", Category: "Write-Ahead Log / Checkpoints", Description: "Sets the minimum size to shrink the WAL to.", Type: IntGUC, Context: ContextSighup, Unit: "MB", Min: 2, Max: maxInt32, Default: "80MB"},
+	{Name: "archive_mode", Category: "Write-Ahead Log / Archiving", Description: "Allows archiving of WAL files using archive_command.", Type: EnumGUC, Context: ContextPostmaster, Default: "off"},
+	{Name: "archive_command", Category: "Write-Ahead Log / Archiving", Description: "Sets the shell command that will be called to archive a WAL file.", Type: StringGUC, Context: ContextSighup},
+
+	// Replication
+	{Name: "max_wal_senders", Category: "Replication / Sending Servers", Description: "Sets the maximum number of simultaneously running WAL sender processes.", Type: IntGUC, Context: ContextPostmaster, Min: 0, Max: 262143, Default: "10"},
+	{Name: "wal_keep_size", Category: "Replication / Sending Servers", Description: "Sets the size of WAL files held for standby servers.", Type: IntGUC, Context: ContextSighup, Unit: "MB", Min: 0, Max: maxInt32, Default: "0"},
+	{Name: "primary_conninfo", Category: "Replication / Standby Servers", Description: "Sets the connection string to be used to connect to the sending server.", Type: StringGUC, Context: ContextSighup},
+	{Name: "hot_standby", Category: "Replication / Standby Servers", Description: "Allows connections and queries during recovery.", Type: BoolGUC, Context: ContextPostmaster, Default: "on"},
+
+	// Query Tuning
+	{Name: "seq_page_cost", Category: "Query Tuning / Planner Cost Constants", Description: "Sets the planner's estimate of the cost of a sequentially fetched disk page.", Type: RealGUC, Context: ContextUser, Min: 0, Max: 1.79769e+308, Default: "1.0"},
+	{Name: "random_page_cost", Category: "Query Tuning / Planner Cost Constants", Description: "Sets the planner's estimate of the cost of a nonsequentially fetched disk page.", Type: RealGUC, Context: ContextUser, Min: 0, Max: 1.79769e+308, Default: "4.0"},
+	{Name: "cpu_operator_cost", Category: "Query Tuning / Planner Cost Constants", Description: "Sets the planner's estimate of the cost of processing each operator or function call.", Type: RealGUC, Context: ContextUser, Min: 0, Max: 1.79769e+308, Default: "0.0025"},
+	{Name: "effective_cache_size", Category: "Query Tuning / Planner Cost Constants", Description: "Sets the planner's assumption about the total size of the data caches.", Type: IntGUC, Context: ContextUser, Unit: "8kB", Min: 1, Max: maxInt32, Default: "4GB"},
+	{Name: "default_statistics_target", Category: "Query Tuning / Other Planner Options", Description: "Sets the default statistics target.", Type: IntGUC, Context: ContextUser, Min: 1, Max: 10000, Default: "100"},
+	{Name: "jit", Category: "Query Tuning / Other Planner Options", Description: "Allow JIT compilation.", Type: BoolGUC, Context: ContextUser, Default: "on"},
+
+	// Reporting and Logging
+	{Name: "log_destination", Category: "Reporting and Logging / Where to Log", Description: "Sets the destination for server log output.", Type: StringGUC, Context: ContextSighup, Default: "stderr"},
+	{Name: "logging_collector", Category: "Reporting and Logging / Where to Log", Description: "Start a subprocess to capture stderr output and/or csvlogs into log files.", Type: BoolGUC, Context: ContextPostmaster, Default: "off"},
+	{Name: "log_min_duration_statement", Category: "Reporting and Logging / When to Log", Description: "Sets the minimum execution time above which all statements will be logged.", Type: IntGUC, Context: ContextSuperuser, Unit: "ms", Min: -1, Max: maxInt32, Default: "-1"},
+	{Name: "application_name", Category: "Reporting and Logging / What to Log", Description: "Sets the application name to be reported in statistics and logs.", Type: StringGUC, Context: ContextUser, MaxLength: nameDataLen},
+	{Name: "log_connections", Category: "Reporting and Logging / What to Log", Description: "Logs each successful connection.", Type: BoolGUC, Context: ContextSuperuser, Default: "off"},
+	{Name: "log_line_prefix", Category: "Reporting and Logging / What to Log", Description: "Controls information prefixed to each log line.", Type: StringGUC, Context: ContextSighup, Default: "%m [%p] "},
+	{Name: "log_timezone", Category: "Reporting and Logging / What to Log", Description: "Sets the time zone to use in log messages.", Type: StringGUC, Context: ContextSighup, Default: "GMT"},
+	{Name: "cluster_name", Category: "Reporting and Logging / Process Title", Description: "Sets the name of the cluster, which is included in the process title.", Type: StringGUC, Context: ContextPostmaster, MaxLength: nameDataLen},
+
+	// Autovacuum
+	{Name: "autovacuum", Category: "Autovacuum", Description: "Starts the autovacuum subprocess.", Type: BoolGUC, Context: ContextSighup, Default: "on"},
+	{Name: "autovacuum_max_workers", Category: "Autovacuum", Description: "Sets the maximum number of simultaneously running autovacuum worker processes.", Type: IntGUC, Context: ContextPostmaster, Min: 1, Max: 262143, Default: "3"},
+	{Name: "autovacuum_freeze_max_age", Category: "Autovacuum", Description: "Age at which to autovacuum a table to prevent transaction ID wraparound.", Type: IntGUC, Context: ContextPostmaster, Min: 100000, Max: 2000000000, Default: "200000000"},
+	{Name: "autovacuum_multixact_freeze_max_age", Category: "Autovacuum", Description: "Multixact age at which to autovacuum a table to prevent multixact wraparound.", Type: IntGUC, Context: ContextPostmaster, Min: 10000, Max: 2000000000, Default: "400000000"},
+
+	// Client Connection Defaults
+	{Name: "search_path", Category: "Client Connection Defaults / Statement Behavior", Description: "Sets the schema search order for names that are not schema-qualified.", Type: StringGUC, Context: ContextUser, Default: `"$user", public`},
+	{Name: "statement_timeout", Category: "Client Connection Defaults / Statement Behavior", Description: "Sets the maximum allowed duration of any statement.", Type: IntGUC, Context: ContextUser, Unit: "ms", Min: 0, Max: maxInt32, Default: "0"},
+	{Name: "timezone", Category: "Client Connection Defaults / Locale and Formatting", Description: "Sets the time zone for displaying and interpreting time stamps.", Type: StringGUC, Context: ContextUser, Default: "GMT"},
+	{Name: "lc_messages", Category: "Client Connection Defaults / Locale and Formatting", Description: "Sets the language in which messages are displayed.", Type: StringGUC, Context: ContextSuperuser},
+	{Name: "lc_monetary", Category: "Client Connection Defaults / Locale and Formatting", Description: "Sets the locale for formatting monetary amounts.", Type: StringGUC, Context: ContextUser, Default: "C"},
+	{Name: "lc_numeric", Category: "Client Connection Defaults / Locale and Formatting", Description: "Sets the locale for formatting numbers.", Type: StringGUC, Context: ContextUser, Default: "C"},
+	{Name: "lc_time", Category: "Client Connection Defaults / Locale and Formatting", Description: "Sets the locale for formatting date and time values.", Type: StringGUC, Context: ContextUser, Default: "C"},
+	{Name: "shared_preload_libraries", Category: "Client Connection Defaults / Shared Library Preloading", Description: "Lists shared libraries to preload into server.", Type: StringGUC, Context: ContextPostmaster},
+}
+
+// LookupGUC returns the registry entry for the parameter with the given name.
+// Names are case insensitive. The second return value is false if the parameter is unknown.
+func LookupGUC(name string) (GUC, bool) {
+	name = NormalizeKey(name)
+	for _, g := range gucs {
+		if g.Name == name {
+			return g, true
+		}
+	}
+	return GUC{}, false
+}
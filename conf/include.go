@@ -0,0 +1,42 @@
+package conf
+
+// Directives for including other configuration files.
+const (
+	DirectiveInclude         = "include"
+	DirectiveIncludeIfExists = "include_if_exists"
+	DirectiveIncludeDir      = "include_dir"
+)
+
+// Include describes an include, include_if_exists or include_dir directive.
+type Include struct {
+	Directive string // One of DirectiveInclude, DirectiveIncludeIfExists or DirectiveIncludeDir
+	Path      string // Dequoted path, as written in the directive
+	Line      int    // 1-based number of the line with the directive
+}
+
+// IncludeDirectives returns the include directives of the configuration, in file order.
+// Quoted paths are dequoted and may contain whitespace, while for unquoted paths only the first
+// token is taken (paths with whitespace must be quoted).
+func (c *Conf) IncludeDirectives() []Include {
+	var result []Include
+	for _, line := range c.Lines() {
+		row, err := c.RowOf(line)
+		if err != nil {
+			continue
+		}
+		key, err := c.Raw(row, keyCol)
+		if err != nil {
+			continue
+		}
+		directive := NormalizeKey(key)
+		if directive != DirectiveInclude && directive != DirectiveIncludeIfExists && directive != DirectiveIncludeDir {
+			continue
+		}
+		path, err := c.String(row, valueCol)
+		if err != nil || path == "" {
+			continue
+		}
+		result = append(result, Include{directive, path, line.Number})
+	}
+	return result
+}
@@ -0,0 +1,24 @@
+package conf_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestIncludeDirectives(t *testing.T) {
+	c := conf.New("include foo.conf\n" +
+		"include_if_exists = 'bar.conf'\n" +
+		"port = 5432\n" +
+		"include_dir 'conf.d/my settings'  # quoted path with a space\n" +
+		"# include 'commented.conf'\n")
+	want := []conf.Include{
+		{Directive: conf.DirectiveInclude, Path: "foo.conf", Line: 1},
+		{Directive: conf.DirectiveIncludeIfExists, Path: "bar.conf", Line: 2},
+		{Directive: conf.DirectiveIncludeDir, Path: "conf.d/my settings", Line: 4},
+	}
+	if got := c.IncludeDirectives(); !reflect.DeepEqual(got, want) {
+		t.Errorf("IncludeDirectives() = %v, want %v", got, want)
+	}
+}
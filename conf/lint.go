@@ -0,0 +1,115 @@
+package conf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/quasoft/pgconf/generic"
+)
+
+// intOrDefault retrieves the integer value of the key, or the default value of the key from
+// the GUC registry, if the key is not set or its value is not an integer. The second return
+// value is the line that sets the key, or 0 if the default value is used.
+func (c *Conf) intOrDefault(key string) (int, int) {
+	if value, err := c.IntK(key); err == nil {
+		line, _ := c.EffectiveLineK(key)
+		return value, line
+	}
+	g, _ := LookupGUC(key)
+	value, _ := strconv.Atoi(g.Default)
+	return value, 0
+}
+
+// LintConnectionReserve returns a warning if the connection slots reserved for superusers
+// (superuser_reserved_connections) and for roles with pg_use_reserved_connections
+// (reserved_connections) leave no slots for ordinary connections, ie. if together they are
+// greater than or equal to max_connections, which PostgreSQL refuses to start with.
+// Settings that are not set are assumed to have their default value.
+func (c *Conf) LintConnectionReserve() []generic.Warning {
+	maxConns, maxLine := c.intOrDefault("max_connections")
+	superuser, superuserLine := c.intOrDefault("superuser_reserved_connections")
+	reserved, reservedLine := c.intOrDefault("reserved_connections")
+	if superuser+reserved < maxConns {
+		return nil
+	}
+
+	return []generic.Warning{{
+		Line: firstLine(superuserLine, reservedLine, maxLine),
+		Message: fmt.Sprintf(
+			"superuser_reserved_connections (%d) plus reserved_connections (%d) must be less than max_connections (%d)",
+			superuser, reserved, maxConns,
+		),
+	}}
+}
+
+// stringOrDefault retrieves the dequoted value of the key, or the default value of the key from
+// the GUC registry, if the key is not set. The second return value is the line that sets the key,
+// or 0 if the default value is used.
+func (c *Conf) stringOrDefault(key string) (string, int) {
+	if value, err := c.StringK(key); err == nil {
+		line, _ := c.EffectiveLineK(key)
+		return strings.TrimSpace(value), line
+	}
+	g, _ := LookupGUC(key)
+	return g.Default, 0
+}
+
+// firstLine returns the first non-zero line number, or 0 if all are zero.
+func firstLine(lines ...int) int {
+	for _, line := range lines {
+		if line != 0 {
+			return line
+		}
+	}
+	return 0
+}
+
+// LintWalSettings returns warnings for common inconsistencies between WAL related settings:
+//  - min_wal_size greater than max_wal_size
+//  - wal_level minimal, while max_wal_senders is greater than 0 or archive_mode is enabled
+//  - checkpoint_completion_target not between 0 and 1 (exclusive)
+// PostgreSQL refuses to start with wal_level minimal in those cases. Settings that are not set
+// are assumed to have their default value, while values that cannot be parsed are not checked.
+func (c *Conf) LintWalSettings() []generic.Warning {
+	var warnings []generic.Warning
+
+	minSize, minLine := c.stringOrDefault("min_wal_size")
+	maxSize, maxLine := c.stringOrDefault("max_wal_size")
+	minBytes, minErr := parseBytes(minSize, "MB", false)
+	maxBytes, maxErr := parseBytes(maxSize, "MB", false)
+	if minErr == nil && maxErr == nil && minBytes > maxBytes {
+		warnings = append(warnings, generic.Warning{
+			Line:    firstLine(minLine, maxLine),
+			Message: fmt.Sprintf("min_wal_size (%s) must not be greater than max_wal_size (%s)", minSize, maxSize),
+		})
+	}
+
+	level, levelLine := c.stringOrDefault("wal_level")
+	if strings.EqualFold(level, "minimal") {
+		senders, sendersLine := c.intOrDefault("max_wal_senders")
+		if senders > 0 {
+			warnings = append(warnings, generic.Warning{
+				Line:    firstLine(levelLine, sendersLine),
+				Message: fmt.Sprintf("wal_level (%s) must be replica or logical, when max_wal_senders (%d) is greater than 0", level, senders),
+			})
+		}
+		archive, archiveLine := c.stringOrDefault("archive_mode")
+		if b, ok := parseBool(archive); (ok && b) || strings.EqualFold(archive, "always") {
+			warnings = append(warnings, generic.Warning{
+				Line:    firstLine(levelLine, archiveLine),
+				Message: fmt.Sprintf("wal_level (%s) must be replica or logical, when archive_mode (%s) is enabled", level, archive),
+			})
+		}
+	}
+
+	target, targetLine := c.stringOrDefault("checkpoint_completion_target")
+	if f, err := strconv.ParseFloat(target, 64); err == nil && (f <= 0 || f >= 1) {
+		warnings = append(warnings, generic.Warning{
+			Line:    targetLine,
+			Message: fmt.Sprintf("checkpoint_completion_target (%s) should be greater than 0 and less than 1", target),
+		})
+	}
+
+	return warnings
+}
@@ -0,0 +1,91 @@
+package conf_test
+
+import (
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestLintConnectionReserve(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantLine int // 0 if no warning is expected
+	}{
+		{"Healthy", "max_connections = 100\nsuperuser_reserved_connections = 3\n", 0},
+		{"Defaults", "", 0},
+		{"Reserve equals max", "max_connections = 10\nsuperuser_reserved_connections = 10\n", 2},
+		{"Reserve with reserved_connections", "max_connections = 10\nsuperuser_reserved_connections = 3\nreserved_connections = 7\n", 2},
+		{"Default reserve", "max_connections = 3\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := conf.New(tt.content).LintConnectionReserve()
+			if tt.wantLine == 0 {
+				if len(warnings) != 0 {
+					t.Errorf("LintConnectionReserve() = %v, want no warnings", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 {
+				t.Fatalf("LintConnectionReserve() = %v, want 1 warning", warnings)
+			}
+			if warnings[0].Line != tt.wantLine {
+				t.Errorf("LintConnectionReserve() warned about line %d, want line %d", warnings[0].Line, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestLintWalSettings(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		wantLines    []int
+		wantMessages []string
+	}{
+		{"Healthy", "min_wal_size = 80MB\nmax_wal_size = 1GB\ncheckpoint_completion_target = 0.9\n", nil, nil},
+		{"Defaults", "", nil, nil},
+		{
+			"Min greater than max",
+			"max_wal_size = 1GB\nmin_wal_size = 2GB\n",
+			[]int{2},
+			[]string{"min_wal_size (2GB) must not be greater than max_wal_size (1GB)"},
+		},
+		{
+			"Min greater than default max",
+			"min_wal_size = 2048\n",
+			[]int{1},
+			[]string{"min_wal_size (2048) must not be greater than max_wal_size (1GB)"},
+		},
+		{
+			"Minimal wal_level with senders and archiving",
+			"wal_level = minimal\narchive_mode = on\n",
+			[]int{1, 1},
+			[]string{
+				"wal_level (minimal) must be replica or logical, when max_wal_senders (10) is greater than 0",
+				"wal_level (minimal) must be replica or logical, when archive_mode (on) is enabled",
+			},
+		},
+		{"Minimal wal_level without senders", "wal_level = minimal\nmax_wal_senders = 0\n", nil, nil},
+		{
+			"Completion target out of range",
+			"port = 5432\ncheckpoint_completion_target = 1.5\n",
+			[]int{2},
+			[]string{"checkpoint_completion_target (1.5) should be greater than 0 and less than 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings := conf.New(tt.content).LintWalSettings()
+			if len(warnings) != len(tt.wantMessages) {
+				t.Fatalf("LintWalSettings() = %v, want %d warnings", warnings, len(tt.wantMessages))
+			}
+			for i, w := range warnings {
+				if w.Line != tt.wantLines[i] || w.Message != tt.wantMessages[i] {
+					t.Errorf("LintWalSettings()[%d] = %d: %q, want %d: %q", i, w.Line, w.Message, tt.wantLines[i], tt.wantMessages[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package conf
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxIncludeDepth is the maximum nesting depth of included files (as in PostgreSQL), which
+// also stops infinite recursion of files that include each other.
+const maxIncludeDepth = 10
+
+// OpenWithIncludes reads the configuration file, along with the files included by its include,
+// include_if_exists and include_dir directives, recursively. The content of every included file
+// is inserted right after the line with its directive, so that settings take precedence in the
+// same order as in PostgreSQL. Relative paths are resolved against the directory of the file
+// with the directive. Missing files of include_if_exists directives are skipped.
+// The file each line was read from can be retrieved with LineSource, and changes can be written
+// back to the files with SaveSources. The files read for include_dir directives can be retrieved
+// with IncludedDirFiles.
+func OpenWithIncludes(filename string) (*Conf, error) {
+	l := &includeLoader{lineSources: []string{}} // Not nil, so that lines are tagged even if all files are empty
+	if err := l.readFile(filename, 0); err != nil {
+		return nil, err
+	}
+
+	c := New(l.content.String())
+	c.sources = l.files
+	if err := c.SetLineTags(l.lineSources); err != nil {
+		return nil, err
+	}
+	c.includedDirs = l.dirs
+	return c, nil
+}
+
+// includedDir describes the files read for an include_dir directive.
+type includedDir struct {
+	path  string   // Path as written in the directive
+	dir   string   // Resolved path of the directory
+	files []string // Files read from the directory, in the order they were read
+}
+
+// includeLoader reads files with include directives for OpenWithIncludes.
+type includeLoader struct {
+	content     strings.Builder
+	files       []string // Files read, in the order they were read
+	lineSources []string // Name of the file every line was read from
+	dirs        []includedDir
+}
+
+// readFile appends the content of the file to the loaded content, expanding its include directives.
+func (l *includeLoader) readFile(filename string, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf("could not read file %s: includes nested too deeply", filename)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("could not read file %s: %s", filename, err)
+	}
+	text := string(data)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n" // Make sure the next file starts on a new line
+	}
+
+	l.files = append(l.files, filename)
+	c := New(text)
+	lines := c.Lines()
+	pos := 0 // Position in text, up to which content was already written
+	for _, inc := range c.IncludeDirectives() {
+		end := lines[inc.Line-1].End
+		l.content.WriteString(text[pos:end])
+		l.lineSources = appendLineSources(l.lineSources, filename, text[pos:end])
+		pos = end
+
+		path := inc.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(filename), path)
+		}
+		switch inc.Directive {
+		case DirectiveIncludeIfExists:
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				continue
+			}
+			fallthrough
+		case DirectiveInclude:
+			err = l.readFile(path, depth+1)
+		case DirectiveIncludeDir:
+			err = l.readDir(inc.Path, path, depth+1)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if pos < len(text) {
+		l.content.WriteString(text[pos:])
+		l.lineSources = appendLineSources(l.lineSources, filename, text[pos:])
+	}
+	return nil
+}
+
+// readDir reads every *.conf file in the directory in lexical order, skipping hidden files, like
+// OpenDir. The path is the path of the directory as written in the include_dir directive.
+func (l *includeLoader) readDir(path, dir string, depth int) error {
+	filenames, err := filepath.Glob(filepath.Join(dir, "*.conf"))
+	if err != nil {
+		return fmt.Errorf("could not list files in %s: %s", dir, err)
+	}
+	d := includedDir{path: path, dir: dir}
+	for _, filename := range filenames {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return fmt.Errorf("could not read file %s: %s", filename, err)
+		}
+		if info.IsDir() || strings.HasPrefix(filepath.Base(filename), ".") {
+			continue
+		}
+		if err := l.readFile(filename, depth); err != nil {
+			return err
+		}
+		d.files = append(d.files, filename)
+	}
+	l.dirs = append(l.dirs, d)
+	return nil
+}
+
+// IncludedDirFiles returns the files read for the include_dir directive with the given path, in
+// the order they were read (lexical order), when the configuration was read with
+// OpenWithIncludes. The path can be given as written in the directive (eg. conf.d) or resolved
+// (eg. /etc/postgresql/conf.d). If several directives include the same directory, the files of the
+// first one are returned. Returns an error if no such directive was loaded.
+func (c *Conf) IncludedDirFiles(directive string) ([]string, error) {
+	for _, d := range c.includedDirs {
+		if d.path == directive || d.dir == filepath.Clean(directive) {
+			return append([]string(nil), d.files...), nil
+		}
+	}
+	return nil, fmt.Errorf("no include_dir directive for %s was loaded", directive)
+}
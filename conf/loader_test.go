@@ -0,0 +1,134 @@
+package conf_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestOpenWithIncludes(t *testing.T) {
+	dir := writeTestDir(t, map[string]string{
+		"postgresql.conf": "port = 5432\ninclude extra.conf\nwork_mem = 4MB\ninclude 'my tuning.conf'\ninclude_if_exists missing.conf\n",
+		"extra.conf":      "work_mem = 64MB\nmax_connections = 200",
+		"my tuning.conf":  "shared_buffers = 1GB\n",
+	})
+	defer os.RemoveAll(dir)
+
+	c, err := conf.OpenWithIncludes(filepath.Join(dir, "postgresql.conf"))
+	if err != nil {
+		t.Fatalf("OpenWithIncludes() errored with '%s', wanted no error", err)
+	}
+
+	if got, err := c.StringK("work_mem"); err != nil || got != "4MB" {
+		t.Errorf("StringK(%q) = %q, %v, want %q set after the include", "work_mem", got, err, "4MB")
+	}
+	if got, err := c.IntK("max_connections"); err != nil || got != 200 {
+		t.Errorf("IntK(%q) = %d, %v, want %d", "max_connections", got, err, 200)
+	}
+	if got, err := c.StringK("shared_buffers"); err != nil || got != "1GB" {
+		t.Errorf("StringK(%q) = %q, %v, want %q", "shared_buffers", got, err, "1GB")
+	}
+
+	wantSources := []string{"postgresql.conf", "postgresql.conf", "extra.conf", "extra.conf", "postgresql.conf", "postgresql.conf", "my tuning.conf", "postgresql.conf"}
+	for i, want := range wantSources {
+		got, err := c.LineSource(i + 1)
+		if err != nil {
+			t.Fatalf("LineSource(%d) errored with '%s', wanted no error", i+1, err)
+		}
+		if got != filepath.Join(dir, want) {
+			t.Errorf("LineSource(%d) = %q, want %q", i+1, got, filepath.Join(dir, want))
+		}
+	}
+
+	if _, err := conf.OpenWithIncludes(filepath.Join(dir, "extra.conf.missing")); err == nil {
+		t.Errorf("OpenWithIncludes() of missing file did not error, wanted error")
+	}
+}
+
+func TestOpenWithIncludes_Recursion(t *testing.T) {
+	dir := writeTestDir(t, map[string]string{
+		"a.conf": "include b.conf\n",
+		"b.conf": "include a.conf\n",
+	})
+	defer os.RemoveAll(dir)
+
+	if _, err := conf.OpenWithIncludes(filepath.Join(dir, "a.conf")); err == nil {
+		t.Errorf("OpenWithIncludes() did not error, wanted error")
+	}
+}
+
+func TestOpenWithIncludes_SaveSources(t *testing.T) {
+	dir := writeTestDir(t, map[string]string{
+		"postgresql.conf": "port = 5432\ninclude 'extra.conf'\nwork_mem = 4MB\n",
+		"extra.conf":      "max_connections = 200\n",
+	})
+	defer os.RemoveAll(dir)
+
+	c, err := conf.OpenWithIncludes(filepath.Join(dir, "postgresql.conf"))
+	if err != nil {
+		t.Fatalf("OpenWithIncludes() errored with '%s', wanted no error", err)
+	}
+	for i := 0; i < 2; i++ {
+		c.SetIntK("max_connections", 300+i)
+		c.SetRawK("work_mem", "8MB")
+		if err := c.SaveSources(); err != nil {
+			t.Fatalf("SaveSources() errored with '%s', wanted no error", err)
+		}
+	}
+
+	want := map[string]string{
+		"postgresql.conf": "port = 5432\ninclude 'extra.conf'\nwork_mem = 8MB\n",
+		"extra.conf":      "max_connections = 301\n",
+	}
+	for name, content := range want {
+		got, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("ReadFile(%q) errored with '%s', wanted no error", name, err)
+		}
+		if string(got) != content {
+			t.Errorf("SaveSources() wrote %q to %s, want %q", got, name, content)
+		}
+	}
+}
+
+func TestIncludedDirFiles(t *testing.T) {
+	dir := writeTestDir(t, map[string]string{
+		"postgresql.conf": "port = 5432\ninclude_dir 'conf.d'\n",
+	})
+	defer os.RemoveAll(dir)
+	confDir := filepath.Join(dir, "conf.d")
+	if err := os.Mkdir(confDir, 0700); err != nil {
+		t.Fatalf("Mkdir(%q) failed: %s", confDir, err)
+	}
+	for _, name := range []string{"30-logging.conf", "10-memory.conf", "20-wal.conf", ".00-hidden.conf", "notes.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(confDir, name), []byte("# "+name+"\n"), 0600); err != nil {
+			t.Fatalf("WriteFile(%q) failed: %s", name, err)
+		}
+	}
+
+	c, err := conf.OpenWithIncludes(filepath.Join(dir, "postgresql.conf"))
+	if err != nil {
+		t.Fatalf("OpenWithIncludes() errored with '%s', wanted no error", err)
+	}
+	want := []string{
+		filepath.Join(confDir, "10-memory.conf"),
+		filepath.Join(confDir, "20-wal.conf"),
+		filepath.Join(confDir, "30-logging.conf"),
+	}
+	for _, directive := range []string{"conf.d", confDir} {
+		got, err := c.IncludedDirFiles(directive)
+		if err != nil {
+			t.Fatalf("IncludedDirFiles(%q) errored with '%s', wanted no error", directive, err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("IncludedDirFiles(%q) = %q, want %q", directive, got, want)
+		}
+	}
+	if _, err := c.IncludedDirFiles("other.d"); err == nil {
+		t.Errorf("IncludedDirFiles(%q) did not error, wanted error", "other.d")
+	}
+}
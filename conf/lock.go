@@ -0,0 +1,53 @@
+package conf
+
+// Clone returns a deep copy of the configuration, including its params and options.
+func (c *Conf) Clone() *Conf {
+	g := *c.Conf
+	return &Conf{
+		Conf:          &g,
+		strict:        c.strict,
+		expandEnv:     c.expandEnv,
+		strictEnv:     c.strictEnv,
+		sensitiveKeys: c.sensitiveKeys,
+		sources:       c.sources,
+		appendTarget:  c.appendTarget,
+		includedDirs:  c.includedDirs,
+		aliases:       c.cloneAliases(),
+	}
+}
+
+// View is the read-only part of the methods of Conf, which WithReadLock passes to its function.
+type View interface {
+	All() string
+	HasK(key string) bool
+	RawK(key string) (string, error)
+	StringK(key string) (string, error)
+	IntK(key string) (int, error)
+	Int64K(key string) (int64, error)
+	Float64K(key string) (float64, error)
+	BoolK(key string) (bool, error)
+	AsBytesK(key string) (int64, error)
+	GetMany(keys ...string) (map[string]string, error)
+	Keys() []string
+	OrderedPairs() [][2]string
+	ToMap() map[string]string
+}
+
+// WithReadLock calls fn while holding a read lock, so that fn sees the configuration in a
+// consistent state, while changes made by concurrent WithLock calls are held off. fn is given a
+// View of c, which exposes only read methods: changes must be made in WithLock instead. Calls to
+// the methods of c outside WithLock and WithReadLock are not guarded by the lock.
+func (c *Conf) WithReadLock(fn func(v View)) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	fn(c)
+}
+
+// WithLock calls fn while holding an exclusive lock, so that changes made by fn are not observed
+// partially by concurrent WithLock or WithReadLock calls. Use WithLock for every change to a
+// configuration shared between goroutines.
+func (c *Conf) WithLock(fn func(c *Conf)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fn(c)
+}
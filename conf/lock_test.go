@@ -0,0 +1,67 @@
+package conf_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestWithReadLock(t *testing.T) {
+	c := conf.New("port = 5432\nmax_connections = 100\n")
+	c.SetAlias("connections", "max_connections")
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			c.WithLock(func(c *conf.Conf) {
+				// Keep both values equal, so readers can detect a partial update
+				c.SetIntK("port", i)
+				c.SetIntK("max_connections", i)
+			})
+		}
+		close(done)
+	}()
+
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				c.WithReadLock(func(v conf.View) {
+					port, _ := v.IntK("port")
+					maxConn, _ := v.IntK("connections")
+					if port != maxConn && port != 5432 {
+						t.Errorf("WithReadLock() sees an inconsistent state: port = %d, max_connections = %d", port, maxConn)
+					}
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	port, _ := c.IntK("port")
+	if port != 99 {
+		t.Errorf("IntK(\"port\") = %d after WithReadLock, want %d", port, 99)
+	}
+}
+
+func TestClone(t *testing.T) {
+	c := conf.New("port = 5432\n")
+	clone := c.Clone()
+	clone.SetIntK("port", 6000)
+	if c.All() != "port = 5432\n" {
+		t.Errorf("Clone() shares changes with the original: %q", c.All())
+	}
+	if clone.All() != "port = 6000\n" {
+		t.Errorf("Clone().SetIntK() = %q, want %q", clone.All(), "port = 6000\n")
+	}
+}
@@ -0,0 +1,42 @@
+package conf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AsPathK retrieves the value of a path setting (eg. data_directory) as a dequoted string.
+// A value that starts with ~ followed by a path separator (or ~ alone) is expanded to the home
+// directory of the current user. Expansion happens only when reading: the stored value is not
+// changed, and PostgreSQL itself does not expand ~ in paths.
+func (c *Conf) AsPathK(key string) (string, error) {
+	value, err := c.StringK(key)
+	if err != nil {
+		return "", err
+	}
+	if value != "~" && !strings.HasPrefix(value, "~/") && !strings.HasPrefix(value, "~"+string(filepath.Separator)) {
+		return value, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not expand ~ in value of key %s: %s", key, err)
+	}
+	return filepath.Join(home, value[1:]), nil
+}
+
+// SetPathK replaces the value of a path setting, enclosing it in quotes. The path is stored
+// as given, without expanding ~ (see AsPathK).
+func (c *Conf) SetPathK(key string, path string) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetPathK", key)(&err)
+	if err := c.validateK(key, path); err != nil {
+		return err
+	}
+	row, err := c.LookupOrAppendK(key)
+	if err != nil {
+		return err
+	}
+	return c.SetRaw(row, valueCol, c.Quote(path))
+}
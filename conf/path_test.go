@@ -0,0 +1,51 @@
+package conf_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestAsPathK(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("UserHomeDir() failed: %s", err)
+	}
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"'~/pgdata'", filepath.Join(home, "pgdata")},
+		{"'~'", home},
+		{"'/var/lib/postgresql/data'", "/var/lib/postgresql/data"},
+		{"'~postgres/data'", "~postgres/data"},
+		{"'data/~/x'", "data/~/x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			c := conf.New("data_directory = " + tt.value + "\n")
+			got, err := c.AsPathK("data_directory")
+			if err != nil {
+				t.Fatalf("AsPathK() errored with '%s', wanted no error", err)
+			}
+			if got != tt.want {
+				t.Errorf("AsPathK() = %q, want %q", got, tt.want)
+			}
+			if raw, _ := c.RawK("data_directory"); raw != tt.value {
+				t.Errorf("AsPathK() changed the stored value to %q, want %q", raw, tt.value)
+			}
+		})
+	}
+}
+
+func TestSetPathK(t *testing.T) {
+	c := conf.New("data_directory = 'ConfigDir'\n")
+	if err := c.SetPathK("data_directory", "~/pg data"); err != nil {
+		t.Fatalf("SetPathK() errored with '%s', wanted no error", err)
+	}
+	if got, want := c.All(), "data_directory = '~/pg data'\n"; got != want {
+		t.Errorf("SetPathK() changed configuration to %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package conf
+
+import "fmt"
+
+// Presets holds built-in tuning profiles that can be applied with ApplyPreset. Values are
+// conservative starting points and should be adjusted to the actual hardware and workload:
+//  - oltp-small: transactional workload on a server with about 1GB of RAM
+//  - oltp-large: transactional workload on a server with about 32GB of RAM
+//  - dw:         data warehouse (analytical) workload on a server with about 32GB of RAM
+var Presets = map[string]map[string]string{
+	"oltp-small": {
+		"max_connections":              "100",
+		"shared_buffers":               "256MB",
+		"effective_cache_size":         "768MB",
+		"work_mem":                     "4MB",
+		"maintenance_work_mem":         "64MB",
+		"checkpoint_completion_target": "0.9",
+		"random_page_cost":             "1.1",
+	},
+	"oltp-large": {
+		"max_connections":              "300",
+		"shared_buffers":               "8GB",
+		"effective_cache_size":         "24GB",
+		"work_mem":                     "16MB",
+		"maintenance_work_mem":         "1GB",
+		"checkpoint_completion_target": "0.9",
+		"max_wal_size":                 "4GB",
+		"random_page_cost":             "1.1",
+	},
+	"dw": {
+		"max_connections":                 "40",
+		"shared_buffers":                  "8GB",
+		"effective_cache_size":            "24GB",
+		"work_mem":                        "256MB",
+		"maintenance_work_mem":            "2GB",
+		"checkpoint_completion_target":    "0.9",
+		"max_wal_size":                    "16GB",
+		"default_statistics_target":       "500",
+		"max_parallel_workers_per_gather": "4",
+		"random_page_cost":                "1.1",
+	},
+}
+
+// ApplyPreset sets all values of the named tuning preset (see Presets) using FromMap.
+// Returns an error if there is no preset with that name.
+func (c *Conf) ApplyPreset(name string) error {
+	preset, ok := Presets[name]
+	if !ok {
+		return fmt.Errorf("unknown preset %s", name)
+	}
+	return c.FromMap(preset)
+}
@@ -0,0 +1,39 @@
+package conf_test
+
+import (
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestApplyPreset(t *testing.T) {
+	c := conf.New("shared_buffers = 128MB # comment\nport = 5432\n")
+	if err := c.ApplyPreset("oltp-small"); err != nil {
+		t.Fatalf("ApplyPreset(\"oltp-small\") errored with '%s', wanted no error", err)
+	}
+
+	for key, want := range conf.Presets["oltp-small"] {
+		got, err := c.StringK(key)
+		if err != nil {
+			t.Errorf("StringK(%q) errored with '%s' after ApplyPreset, wanted no error", key, err)
+		} else if got != want {
+			t.Errorf("StringK(%q) = %q after ApplyPreset, want %q", key, got, want)
+		}
+	}
+	if got := readLine(t, c.All(), 1); got != "shared_buffers = 256MB # comment" {
+		t.Errorf("ApplyPreset() changed line #1 to %q, want %q", got, "shared_buffers = 256MB # comment")
+	}
+	if port, _ := c.IntK("port"); port != 5432 {
+		t.Errorf("IntK(\"port\") = %d after ApplyPreset, want %d", port, 5432)
+	}
+}
+
+func TestApplyPreset_Unknown(t *testing.T) {
+	c := conf.New("port = 5432\n")
+	if err := c.ApplyPreset("no-such-preset"); err == nil {
+		t.Errorf("ApplyPreset(\"no-such-preset\") did not error, wanted error")
+	}
+	if c.All() != "port = 5432\n" {
+		t.Errorf("ApplyPreset(\"no-such-preset\") changed configuration to %q", c.All())
+	}
+}
@@ -0,0 +1,91 @@
+package conf
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue is the mask that replaces sensitive values in redacted output.
+const RedactedValue = "****"
+
+// DefaultSensitiveKeys lists the default names that mark a key as sensitive (see SetSensitiveKeys).
+var DefaultSensitiveKeys = []string{"password", "passphrase", "passphrase_command", "secret"}
+
+// conninfoKeys lists keys holding libpq connection strings, which may embed passwords.
+var conninfoKeys = []string{"primary_conninfo"}
+
+// conninfoPassword matches the password field of a libpq connection string.
+var conninfoPassword = regexp.MustCompile(`(password\s*=\s*)('(?:[^'\\]|\\.)*'|[^\s']+)`)
+
+// SetSensitiveKeys replaces the list of names used to recognize sensitive keys when redacting
+// values. A key is considered sensitive if it equals any of the names, or ends with an underscore
+// followed by the name (case insensitive), so "password" matches ldap_password, but not
+// password_encryption. By default DefaultSensitiveKeys is used.
+func (c *Conf) SetSensitiveKeys(keys ...string) {
+	c.sensitiveKeys = keys
+}
+
+// isSensitiveK tests if values of the key should be masked when redacting.
+func (c *Conf) isSensitiveK(key string) bool {
+	keys := c.sensitiveKeys
+	if keys == nil {
+		keys = DefaultSensitiveKeys
+	}
+	key = NormalizeKey(key)
+	for _, k := range keys {
+		k = NormalizeKey(k)
+		if k != "" && (key == k || strings.HasSuffix(key, "_"+k)) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue returns the masked value for the key. The second return value is false if the
+// value contains nothing sensitive.
+func (c *Conf) redactValue(key, value string) (string, bool) {
+	if c.isSensitiveK(key) {
+		return RedactedValue, true
+	}
+	for _, k := range conninfoKeys {
+		if NormalizeKey(key) == k && conninfoPassword.MatchString(value) {
+			return conninfoPassword.ReplaceAllString(value, "${1}"+RedactedValue), true
+		}
+	}
+	return "", false
+}
+
+// Redacted returns the whole configuration with values of sensitive keys masked, and with
+// passwords in connection strings (eg. primary_conninfo) masked. Layout is preserved, so line
+// numbers match the original configuration. The configuration itself is not modified.
+func (c *Conf) Redacted() (string, error) {
+	clone := c.Clone()
+	_, err := clone.MapValues(clone.redactValue)
+	if err != nil {
+		return "", err
+	}
+	return clone.All(), nil
+}
+
+// WriteRedacted writes the whole configuration to w like WriteTo, but with the values of the
+// given keys masked, in addition to the values masked by Redacted (sensitive keys and passwords
+// in connection strings). Keys are matched by name (case insensitive). Layout is preserved and
+// the configuration itself is not modified.
+func (c *Conf) WriteRedacted(w io.Writer, sensitiveKeys []string) (int64, error) {
+	names := make(map[string]bool)
+	for _, key := range sensitiveKeys {
+		names[NormalizeKey(key)] = true
+	}
+	clone := c.Clone()
+	_, err := clone.MapValues(func(key, value string) (string, bool) {
+		if names[NormalizeKey(key)] {
+			return RedactedValue, true
+		}
+		return clone.redactValue(key, value)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return clone.WriteTo(w)
+}
@@ -0,0 +1,132 @@
+package conf_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestRedacted(t *testing.T) {
+	content := "port = 5432\n" +
+		"primary_conninfo = 'host=10.0.0.1 user=replicator password=s3cr3t application_name=standby'\n" +
+		"ssl_passphrase_command = 'echo secret'   # comment\n" +
+		"application_name = 'app'\n"
+	c := conf.New(content)
+
+	got, err := c.Redacted()
+	if err != nil {
+		t.Fatalf("Redacted() errored with '%s', wanted no error", err)
+	}
+	want := "port = 5432\n" +
+		"primary_conninfo = 'host=10.0.0.1 user=replicator password=**** application_name=standby'\n" +
+		"ssl_passphrase_command = '****'   # comment\n" +
+		"application_name = 'app'\n"
+	if got != want {
+		t.Errorf("Redacted() = %q, want %q", got, want)
+	}
+	if c.All() != content {
+		t.Errorf("Redacted() modified the configuration to %q", c.All())
+	}
+}
+
+func TestRedacted_QuotedPassword(t *testing.T) {
+	c := conf.New("primary_conninfo = 'user=replicator password=''s3 cr3t'' host=a'\n")
+	got, err := c.Redacted()
+	if err != nil {
+		t.Fatalf("Redacted() errored with '%s', wanted no error", err)
+	}
+	want := "primary_conninfo = 'user=replicator password=**** host=a'\n"
+	if got != want {
+		t.Errorf("Redacted() = %q, want %q", got, want)
+	}
+}
+
+func TestRedacted_MatchesWholeNames(t *testing.T) {
+	content := "password_encryption = scram-sha-256\n" +
+		"ldap_password = 'hunter2'\n" +
+		"secret_santa = on\n"
+	c := conf.New(content)
+
+	got, err := c.Redacted()
+	if err != nil {
+		t.Fatalf("Redacted() errored with '%s', wanted no error", err)
+	}
+	want := "password_encryption = scram-sha-256\n" +
+		"ldap_password = '****'\n" +
+		"secret_santa = on\n"
+	if got != want {
+		t.Errorf("Redacted() = %q, want %q", got, want)
+	}
+}
+
+func TestRedacted_KeepsParams(t *testing.T) {
+	c := conf.New("ldap_password = hunter2\n")
+	params := c.Params()
+	params.AlwaysQuoteStrings = true
+	c.SetParams(params)
+
+	got, err := c.Redacted()
+	if err != nil {
+		t.Fatalf("Redacted() errored with '%s', wanted no error", err)
+	}
+	want := "ldap_password = '****'\n"
+	if got != want {
+		t.Errorf("Redacted() = %q, want %q", got, want)
+	}
+}
+
+func TestSetSensitiveKeys(t *testing.T) {
+	c := conf.New("api_token = 'abc'\nldap_password = 'def'\n")
+	c.SetSensitiveKeys("token")
+
+	got, err := c.Redacted()
+	if err != nil {
+		t.Fatalf("Redacted() errored with '%s', wanted no error", err)
+	}
+	want := "api_token = '****'\nldap_password = 'def'\n"
+	if got != want {
+		t.Errorf("Redacted() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteRedacted(t *testing.T) {
+	content := "ssl_key_file = 'server.key'\n" +
+		"ldap_password = 'hunter2' # inline\n" +
+		"port = 5432\n"
+	c := conf.New(content)
+
+	var b strings.Builder
+	n, err := c.WriteRedacted(&b, []string{"SSL_Key_File"})
+	if err != nil {
+		t.Fatalf("WriteRedacted() errored with '%s', wanted no error", err)
+	}
+	want := "ssl_key_file = '****'\n" +
+		"ldap_password = '****' # inline\n" +
+		"port = 5432\n"
+	if got := b.String(); got != want {
+		t.Errorf("WriteRedacted() wrote %q, want %q", got, want)
+	}
+	if n != int64(len(want)) {
+		t.Errorf("WriteRedacted() = %d, want %d", n, len(want))
+	}
+	if c.All() != content {
+		t.Errorf("WriteRedacted() modified the configuration to %q", c.All())
+	}
+}
+
+func TestWriteRedacted_KeepsParams(t *testing.T) {
+	c := conf.New("password_encryption = scram-sha-256\nssl_key_file = server.key\n")
+	params := c.Params()
+	params.AlwaysQuoteStrings = true
+	c.SetParams(params)
+
+	var b strings.Builder
+	if _, err := c.WriteRedacted(&b, []string{"ssl_key_file"}); err != nil {
+		t.Fatalf("WriteRedacted() errored with '%s', wanted no error", err)
+	}
+	want := "password_encryption = scram-sha-256\nssl_key_file = '****'\n"
+	if got := b.String(); got != want {
+		t.Errorf("WriteRedacted() wrote %q, want %q", got, want)
+	}
+}
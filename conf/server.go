@@ -0,0 +1,102 @@
+package conf
+
+import "github.com/quasoft/pgconf/generic"
+
+// ServerSettings holds the values of commonly used settings. Memory settings are in bytes.
+// Fields with zero values are treated as not set, so all fields are marked omitempty.
+type ServerSettings struct {
+	ListenAddresses    []string `json:"listen_addresses,omitempty"`
+	Port               int      `json:"port,omitempty"`
+	MaxConnections     int      `json:"max_connections,omitempty"`
+	SharedBuffers      int64    `json:"shared_buffers,omitempty"`
+	WorkMem            int64    `json:"work_mem,omitempty"`
+	MaintenanceWorkMem int64    `json:"maintenance_work_mem,omitempty"`
+	EffectiveCacheSize int64    `json:"effective_cache_size,omitempty"`
+	WalLevel           string   `json:"wal_level,omitempty"`
+	MaxWalSenders      int      `json:"max_wal_senders,omitempty"`
+	LogDestination     string   `json:"log_destination,omitempty"`
+}
+
+// LoadServerSettings reads the commonly used settings into a ServerSettings structure, using the
+// typed accessors (eg. AsBytesK for memory settings). Settings that are not set are left with
+// their zero value. Returns an error if a setting is set, but its value cannot be parsed.
+func (c *Conf) LoadServerSettings() (ServerSettings, error) {
+	var s ServerSettings
+	var err error // First error other than ErrKeyNotFound
+	ignoreNotFound := func(e error) {
+		if e != nil && e != generic.ErrKeyNotFound && err == nil {
+			err = e
+		}
+	}
+
+	var e error
+	s.ListenAddresses, e = c.AsStringSliceK("listen_addresses")
+	ignoreNotFound(e)
+	s.Port, e = c.IntK("port")
+	ignoreNotFound(e)
+	s.MaxConnections, e = c.IntK("max_connections")
+	ignoreNotFound(e)
+	s.SharedBuffers, e = c.AsBytesK("shared_buffers")
+	ignoreNotFound(e)
+	s.WorkMem, e = c.AsBytesK("work_mem")
+	ignoreNotFound(e)
+	s.MaintenanceWorkMem, e = c.AsBytesK("maintenance_work_mem")
+	ignoreNotFound(e)
+	s.EffectiveCacheSize, e = c.AsBytesK("effective_cache_size")
+	ignoreNotFound(e)
+	s.WalLevel, e = c.StringK("wal_level")
+	ignoreNotFound(e)
+	s.MaxWalSenders, e = c.IntK("max_wal_senders")
+	ignoreNotFound(e)
+	s.LogDestination, e = c.StringK("log_destination")
+	ignoreNotFound(e)
+
+	if err != nil {
+		return ServerSettings{}, err
+	}
+	return s, nil
+}
+
+// SaveServerSettings writes the commonly used settings from the ServerSettings structure, using
+// the typed setters (eg. SetBytesK for memory settings). Existing settings are updated and missing
+// ones are appended. Fields with zero values are skipped (see omitempty on ServerSettings).
+func (c *Conf) SaveServerSettings(s ServerSettings) error {
+	var err error // First error
+	set := func(e error) {
+		if e != nil && err == nil {
+			err = e
+		}
+	}
+
+	if len(s.ListenAddresses) > 0 {
+		set(c.SetListenAddresses(s.ListenAddresses...))
+	}
+	if s.Port != 0 {
+		set(c.SetIntK("port", s.Port))
+	}
+	if s.MaxConnections != 0 {
+		set(c.SetIntK("max_connections", s.MaxConnections))
+	}
+	if s.SharedBuffers != 0 {
+		set(c.SetBytesK("shared_buffers", s.SharedBuffers))
+	}
+	if s.WorkMem != 0 {
+		set(c.SetBytesK("work_mem", s.WorkMem))
+	}
+	if s.MaintenanceWorkMem != 0 {
+		set(c.SetBytesK("maintenance_work_mem", s.MaintenanceWorkMem))
+	}
+	if s.EffectiveCacheSize != 0 {
+		set(c.SetBytesK("effective_cache_size", s.EffectiveCacheSize))
+	}
+	if s.WalLevel != "" {
+		set(c.SetStringK("wal_level", s.WalLevel))
+	}
+	if s.MaxWalSenders != 0 {
+		set(c.SetIntK("max_wal_senders", s.MaxWalSenders))
+	}
+	if s.LogDestination != "" {
+		set(c.SetStringK("log_destination", s.LogDestination))
+	}
+	return err
+}
@@ -0,0 +1,86 @@
+package conf_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestLoadServerSettings(t *testing.T) {
+	c := openTestFile(t, "postgresql.conf")
+
+	got, err := c.LoadServerSettings()
+	if err != nil {
+		t.Fatalf("LoadServerSettings() errored with '%s', wanted no error", err)
+	}
+	want := conf.ServerSettings{
+		ListenAddresses: []string{"*"},
+		Port:            5432,
+		MaxConnections:  100,
+		SharedBuffers:   128 * 1024 * 1024,
+		MaxWalSenders:   10,
+		LogDestination:  "syslog",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadServerSettings() = %+v, want %+v", got, want)
+	}
+
+	if _, err := conf.New("work_mem = 4XB\n").LoadServerSettings(); err == nil {
+		t.Errorf("LoadServerSettings() with an invalid unit did not error, wanted error")
+	}
+}
+
+func TestSaveServerSettings(t *testing.T) {
+	c := openTestFile(t, "postgresql.conf")
+	want := conf.ServerSettings{
+		ListenAddresses:    []string{"localhost", "10.0.0.1"},
+		Port:               5433,
+		MaxConnections:     200,
+		SharedBuffers:      3 << 30,
+		WorkMem:            1536 << 10,
+		MaintenanceWorkMem: 256 << 20,
+		WalLevel:           "logical",
+		MaxWalSenders:      10,
+		LogDestination:     "syslog",
+	}
+	if err := c.SaveServerSettings(want); err != nil {
+		t.Fatalf("SaveServerSettings() errored with '%s', wanted no error", err)
+	}
+
+	if raw, _ := c.RawK("shared_buffers"); raw != "3GB" {
+		t.Errorf("RawK(%q) = %q after save, want %q", "shared_buffers", raw, "3GB")
+	}
+	if raw, _ := c.RawK("work_mem"); raw != "1536kB" {
+		t.Errorf("RawK(%q) = %q after save, want %q", "work_mem", raw, "1536kB")
+	}
+
+	got, err := conf.New(c.All()).LoadServerSettings()
+	if err != nil {
+		t.Fatalf("LoadServerSettings() errored with '%s', wanted no error", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadServerSettings() after save = %+v, want %+v", got, want)
+	}
+}
+
+func TestSaveServerSettings_OmitEmpty(t *testing.T) {
+	content := "port = 5432\nwal_level = replica\n"
+	c := conf.New(content)
+	s := conf.ServerSettings{MaxConnections: 50}
+	if err := c.SaveServerSettings(s); err != nil {
+		t.Fatalf("SaveServerSettings() errored with '%s', wanted no error", err)
+	}
+	if got, want := c.All(), content+"max_connections = 50"; got != want {
+		t.Errorf("SaveServerSettings() changed configuration to %q, want %q", got, want)
+	}
+
+	got, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal() errored with '%s', wanted no error", err)
+	}
+	if want := `{"max_connections":50}`; string(got) != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+}
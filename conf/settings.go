@@ -0,0 +1,344 @@
+package conf
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/quasoft/pgconf/generic"
+)
+
+// setting describes a line with an active key and value.
+type setting struct {
+	line generic.Line
+	row  *generic.Row
+	key  string
+}
+
+// settings returns all lines with an active key and value, in file order.
+// Keys without values are skipped.
+func (c *Conf) settings() []setting {
+	var result []setting
+	for _, line := range c.Lines() {
+		row, err := c.RowOf(line)
+		if err != nil || !row.HasColumn(valueCol) {
+			continue
+		}
+		key, err := c.Raw(row, keyCol)
+		if err != nil {
+			continue
+		}
+		result = append(result, setting{line, c.joinUnquotedValue(row), key})
+	}
+	return result
+}
+
+// effectiveSettings returns the active settings that provide the effective value of each key
+// (the last active occurrence of the key), ordered by their position in the file.
+func (c *Conf) effectiveSettings() []setting {
+	all := c.settings()
+	last := make(map[string]int)
+	for i, s := range all {
+		last[NormalizeKey(s.key)] = i
+	}
+	var result []setting
+	for i, s := range all {
+		if last[NormalizeKey(s.key)] == i {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// ToMap returns the effective value of every active setting as a dequoted string, keyed by the
+// setting name as written on its last active line. The order of settings is lost (see OrderedPairs).
+func (c *Conf) ToMap() map[string]string {
+	m := make(map[string]string)
+	for _, p := range c.OrderedPairs() {
+		m[p[0]] = p[1]
+	}
+	return m
+}
+
+// FromMap sets the value of every key in the map, updating existing keys and appending missing ones.
+// Values that need no quoting (eg. 5432 or 256MB) are set as is, the rest are quoted (as by
+// SetStringK). Keys are processed in sorted order, so that appended lines are deterministic.
+func (c *Conf) FromMap(m map[string]string) error {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := c.SetRawK(key, c.quoteIfNeeded(m[key])); err != nil {
+			return fmt.Errorf("could not set %s: %s", key, err)
+		}
+	}
+	return nil
+}
+
+// quoteIfNeeded returns the value quoted, if it would not be read back unchanged as a raw value,
+// ie. if it is empty or contains whitespace, quotes or comment characters.
+func (c *Conf) quoteIfNeeded(value string) string {
+	if value == "" || c.HasQuotesOrWhitespace(value) || strings.ContainsRune(value, c.Params().InlineComment) {
+		return c.Quote(value)
+	}
+	return value
+}
+
+// OrderedPairs returns the effective key/value pairs in file order. Values are dequoted.
+// A key set multiple times appears once, at the position of its last active line, with
+// the value from that line.
+func (c *Conf) OrderedPairs() [][2]string {
+	var pairs [][2]string
+	for _, s := range c.effectiveSettings() {
+		value, err := c.String(s.row, valueCol)
+		if err != nil {
+			continue
+		}
+		pairs = append(pairs, [2]string{s.key, value})
+	}
+	return pairs
+}
+
+// Keys returns the names of all active settings in file order, dequoted. A key set multiple times
+// appears once, at the position of its last active line and as written there, like in
+// OrderedPairs. Keys without values are skipped.
+func (c *Conf) Keys() []string {
+	var keys []string
+	for _, s := range c.effectiveSettings() {
+		keys = append(keys, c.Dequote(s.key))
+	}
+	return keys
+}
+
+// MapValues calls fn for each active setting with the key and the dequoted value, in file order.
+// If fn returns true, the value is replaced with the returned string (quoted as by SetStringK),
+// otherwise the value is left unchanged. Returns the number of values replaced.
+func (c *Conf) MapValues(fn func(key, value string) (string, bool)) (count int, err error) {
+	defer c.logChangeAll("MapValues")(&err)
+	type change struct {
+		row   *generic.Row
+		value string
+	}
+	var changes []change
+	for _, s := range c.settings() {
+		value, err := c.String(s.row, valueCol)
+		if err != nil {
+			return 0, err
+		}
+		if newValue, ok := fn(s.key, value); ok {
+			changes = append(changes, change{s.row, newValue})
+		}
+	}
+
+	// Apply changes in reverse order, so that positions of preceding rows remain valid
+	for i := len(changes) - 1; i >= 0; i-- {
+		if err := c.SetString(changes[i].row, valueCol, changes[i].value); err != nil {
+			return 0, err
+		}
+	}
+	return len(changes), nil
+}
+
+// NormalizeDelimiters rewrites the delimiter between the key and the value of every active setting
+// to the canonical delimiter in Params.DefaultDelim (" = " by default), preserving keys, values and
+// inline comments. Returns the number of lines changed.
+func (c *Conf) NormalizeDelimiters() (count int, err error) {
+	defer c.logChangeAll("NormalizeDelimiters")(&err)
+	settings := c.settings()
+	// Iterate in reverse order, so that positions of preceding rows remain valid
+	for i := len(settings) - 1; i >= 0; i-- {
+		changed, err := c.NormalizeDelim(settings[i].row, keyCol)
+		if err != nil {
+			return count, err
+		}
+		if changed {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// KeepOnly removes every active setting whose key is not in the allowlist (case-insensitive),
+// preserving comments, blank lines and commented-out settings. Returns the number of lines removed.
+func (c *Conf) KeepOnly(keys ...string) (count int, err error) {
+	keys, done := c.aliasScopeSlice(keys)
+	defer done()
+	defer c.logChangeAll("KeepOnly")(&err)
+	allowed := make(map[string]bool)
+	for _, key := range keys {
+		allowed[NormalizeKey(key)] = true
+	}
+	settings := c.settings()
+	// Iterate in reverse order, so that positions of preceding lines remain valid
+	for i := len(settings) - 1; i >= 0; i-- {
+		if allowed[NormalizeKey(settings[i].key)] {
+			continue
+		}
+		if err := c.RemoveLine(settings[i].line); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// DeleteK removes the whole line that sets the effective value of the key (the last active
+// occurrence, as found by LookupKey), including its indentation and EOL character, leaving the
+// rest of the configuration intact. If the removed line is the last one and has no EOL character,
+// the EOL character of the preceding line is removed instead, so that no empty line is left behind.
+// Returns generic.ErrKeyNotFound if the key is not set.
+func (c *Conf) DeleteK(key string) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("DeleteK", key)(&err)
+	number, err := c.EffectiveLineK(key)
+	if err != nil {
+		return err
+	}
+	line, err := c.LineAt(number)
+	if err != nil {
+		return err
+	}
+	if line.End == len(c.All()) && !strings.HasSuffix(c.All(), "\n") && line.Start > 0 {
+		prev, err := c.LineAt(number - 1)
+		if err != nil {
+			return err
+		}
+		eol := len(prev.Text) - len(strings.TrimSuffix(prev.Text, "\r")) + 1
+		line.Start -= eol
+	}
+	return c.RemoveLine(line)
+}
+
+// EffectiveLineK returns the 1-based number of the line that provides the effective value of the
+// key, which is the last active occurrence of the key with a value.
+// Returns generic.ErrKeyNotFound if the key is not set.
+func (c *Conf) EffectiveLineK(key string) (int, error) {
+	key = c.resolveAliasK(key)
+	number := 0
+	for _, s := range c.settings() {
+		if NormalizeKey(s.key) == NormalizeKey(key) {
+			number = s.line.Number
+		}
+	}
+	if number == 0 {
+		return 0, generic.ErrKeyNotFound
+	}
+	return number, nil
+}
+
+// GetMany retrieves the dequoted values of all requested keys in a single pass over the
+// configuration, which is faster than calling StringK for each key on large files. The returned
+// map is keyed by the requested key names and contains only the keys that are set. As with
+// StringK, the last active occurrence of a key wins.
+func (c *Conf) GetMany(keys ...string) (map[string]string, error) {
+	requested := make(map[string][]string)
+	for _, key := range keys {
+		name := NormalizeKey(c.resolveAliasK(key))
+		requested[name] = append(requested[name], key)
+	}
+	values := make(map[string]string)
+	for _, s := range c.settings() {
+		names, ok := requested[NormalizeKey(s.key)]
+		if !ok {
+			continue
+		}
+		value, err := c.String(s.row, valueCol)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = c.expandEnvK(s.key, value); err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+// TidyBlankLines collapses every run of consecutive blank lines into a single blank line,
+// keeping single blank lines that separate sections. Returns the number of lines removed.
+func (c *Conf) TidyBlankLines() int {
+	var err error
+	defer c.logChangeAll("TidyBlankLines")(&err)
+	return c.CollapseBlankLines(1)
+}
+
+// Duplicates returns the 1-based line numbers of every active occurrence of keys that are set on
+// more than one line, keyed by the normalized key name (see NormalizeKey). Only the last occurrence
+// of such keys is effective (see EffectiveLineK).
+func (c *Conf) Duplicates() map[string][]int {
+	lines := make(map[string][]int)
+	for _, s := range c.settings() {
+		key := NormalizeKey(s.key)
+		lines[key] = append(lines[key], s.line.Number)
+	}
+	for key, numbers := range lines {
+		if len(numbers) < 2 {
+			delete(lines, key)
+		}
+	}
+	return lines
+}
+
+// RequireUnique returns an error listing every key that is set on more than one active line,
+// along with the line numbers, or nil if every key is set at most once. Useful for refusing to
+// operate on files with shadowed settings.
+func (c *Conf) RequireUnique() error {
+	duplicates := c.Duplicates()
+	if len(duplicates) == 0 {
+		return nil
+	}
+	var keys []string
+	for key := range duplicates {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	problems := make([]string, len(keys))
+	for i, key := range keys {
+		numbers := make([]string, len(duplicates[key]))
+		for j, n := range duplicates[key] {
+			numbers[j] = strconv.Itoa(n)
+		}
+		problems[i] = fmt.Sprintf("%s on lines %s", key, strings.Join(numbers, ", "))
+	}
+	return fmt.Errorf("keys set more than once: %s", strings.Join(problems, "; "))
+}
+
+// Occurrence describes a line, on which a key appears.
+type Occurrence struct {
+	Line      int  // 1-based line number
+	Commented bool // Whether the key is commented out (eg. #port = 5432)
+}
+
+// OccurrenceLines returns every line, on which the key appears, in file order, either active
+// (with or without a value) or commented out (as recognized by CommentedKeys). The effective
+// value comes from the last active occurrence with a value (see EffectiveLineK).
+// Returns generic.ErrKeyNotFound if the key does not appear at all.
+func (c *Conf) OccurrenceLines(key string) ([]Occurrence, error) {
+	key = NormalizeKey(c.resolveAliasK(key))
+	var result []Occurrence
+	for _, line := range c.Lines() {
+		commented := false
+		row, err := c.RowOf(line)
+		if err != nil {
+			var ok bool
+			if row, ok = c.commentedSettingRow(line); !ok {
+				continue
+			}
+			commented = true
+		}
+		k, err := c.Raw(row, keyCol)
+		if err != nil || NormalizeKey(k) != key {
+			continue
+		}
+		result = append(result, Occurrence{line.Number, commented})
+	}
+	if len(result) == 0 {
+		return nil, generic.ErrKeyNotFound
+	}
+	return result, nil
+}
@@ -0,0 +1,326 @@
+package conf_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+	"github.com/quasoft/pgconf/generic"
+)
+
+func TestMapValues(t *testing.T) {
+	c := conf.New("port = 5432\n" +
+		"ldap_password = 'secret' # comment\n" +
+		"# db_password = 'commented'\n" +
+		"application_name = 'app'\n" +
+		"replication_password=other\n")
+
+	n, err := c.MapValues(func(key, value string) (string, bool) {
+		if strings.Contains(key, "password") {
+			return "****", true
+		}
+		return "", false
+	})
+	if err != nil {
+		t.Fatalf("MapValues() errored with '%s', wanted no error", err)
+	}
+	if n != 2 {
+		t.Errorf("MapValues() = %d, want %d", n, 2)
+	}
+
+	want := "port = 5432\n" +
+		"ldap_password = '****' # comment\n" +
+		"# db_password = 'commented'\n" +
+		"application_name = 'app'\n" +
+		"replication_password='****'\n"
+	if got := c.All(); got != want {
+		t.Errorf("MapValues() changed configuration to %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeDelimiters(t *testing.T) {
+	c := conf.New("port=5432\n" +
+		"max_connections   =   100     # comment stays put\n" +
+		"# a = b\n" +
+		"shared_buffers = 128MB\n" +
+		"log_connections yes\n")
+
+	n, err := c.NormalizeDelimiters()
+	if err != nil {
+		t.Fatalf("NormalizeDelimiters() errored with '%s', wanted no error", err)
+	}
+	if n != 3 {
+		t.Errorf("NormalizeDelimiters() = %d, want %d", n, 3)
+	}
+
+	want := "port = 5432\n" +
+		"max_connections = 100     # comment stays put\n" +
+		"# a = b\n" +
+		"shared_buffers = 128MB\n" +
+		"log_connections = yes\n"
+	if got := c.All(); got != want {
+		t.Errorf("NormalizeDelimiters() changed configuration to %q, want %q", got, want)
+	}
+}
+
+func TestOrderedPairs(t *testing.T) {
+	c := conf.New("port = 5432\n" +
+		"work_mem = 4MB\n" +
+		"# comment\n" +
+		"listen_addresses = '*'\n" +
+		"invalid_key_without_value\n" +
+		"work_mem = '16MB' # duplicate\n")
+
+	got := c.OrderedPairs()
+	want := [][2]string{
+		{"port", "5432"},
+		{"listen_addresses", "*"},
+		{"work_mem", "16MB"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("OrderedPairs() = %q, want %q", got, want)
+	}
+
+	gotMap := c.ToMap()
+	wantMap := map[string]string{"port": "5432", "listen_addresses": "*", "work_mem": "16MB"}
+	if !reflect.DeepEqual(gotMap, wantMap) {
+		t.Errorf("ToMap() = %q, want %q", gotMap, wantMap)
+	}
+}
+
+func TestFromMap(t *testing.T) {
+	c := conf.New("port = 5432 # comment\n")
+	err := c.FromMap(map[string]string{
+		"port":            "5433",
+		"log_line_prefix": "%m [%p] ",
+		"max_connections": "100",
+		"ssl_ca_file":     "",
+	})
+	if err != nil {
+		t.Fatalf("FromMap() errored with '%s', wanted no error", err)
+	}
+	want := "port = 5433 # comment\n" +
+		"log_line_prefix = '%m [%p] '\n" +
+		"max_connections = 100\n" +
+		"ssl_ca_file = ''"
+	if got := c.All(); got != want {
+		t.Errorf("FromMap() changed configuration to %q, want %q", got, want)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	c := conf.New("port = 5432\n" +
+		"Work_Mem = 4MB\n" +
+		"# comment\n" +
+		"#fsync = off\n" +
+		"\n" +
+		"'listen_addresses' = '*' # quoted key\n" +
+		"invalid_key_without_value\n" +
+		"work_mem = '16MB' # duplicate\n")
+
+	got := c.Keys()
+	want := []string{"port", "listen_addresses", "work_mem"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Keys() = %q, want %q", got, want)
+	}
+	var pairKeys []string
+	for _, p := range c.OrderedPairs() {
+		pairKeys = append(pairKeys, c.Dequote(p[0]))
+	}
+	if !reflect.DeepEqual(got, pairKeys) {
+		t.Errorf("Keys() = %q, want the keys of OrderedPairs() %q", got, pairKeys)
+	}
+}
+
+func TestKeepOnly(t *testing.T) {
+	c := conf.New("# Managed settings\n" +
+		"listen_addresses = '*'\n" +
+		"port = 5432\n" +
+		"max_connections = 100 # connections\n" +
+		"\n" +
+		"# shared_buffers = 128MB\n" +
+		"shared_buffers = 256MB\n" +
+		"work_mem = 4MB\n" +
+		"Port = 5433\n")
+
+	n, err := c.KeepOnly("port", "max_connections", "work_mem")
+	if err != nil {
+		t.Fatalf("KeepOnly() errored with '%s', wanted no error", err)
+	}
+	if n != 2 {
+		t.Errorf("KeepOnly() = %d, want %d", n, 2)
+	}
+
+	want := "# Managed settings\n" +
+		"port = 5432\n" +
+		"max_connections = 100 # connections\n" +
+		"\n" +
+		"# shared_buffers = 128MB\n" +
+		"work_mem = 4MB\n" +
+		"Port = 5433\n"
+	if got := c.All(); got != want {
+		t.Errorf("KeepOnly() changed configuration to %q, want %q", got, want)
+	}
+}
+
+func TestEffectiveLineK(t *testing.T) {
+	c := conf.New("work_mem = 4MB\n" +
+		"# work_mem = 8MB\n" +
+		"port = 5432\n" +
+		"Work_Mem = 16MB\n" +
+		"work_mem\n")
+
+	tests := []struct {
+		key     string
+		want    int
+		wantErr error
+	}{
+		{"work_mem", 4, nil},
+		{"port", 3, nil},
+		{"fsync", 0, generic.ErrKeyNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := c.EffectiveLineK(tt.key)
+			if err != tt.wantErr {
+				t.Fatalf("EffectiveLineK(%q) errored with '%v', want '%v'", tt.key, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("EffectiveLineK(%q) = %d, want %d", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	c := openTestFile(t, "postgresql.conf")
+
+	got, err := c.GetMany("port", "Max_Connections", "listen_addresses", "no_such_key", "shared_buffers")
+	if err != nil {
+		t.Fatalf("GetMany() errored with '%s', wanted no error", err)
+	}
+	want := map[string]string{
+		"port":             "5432",
+		"Max_Connections":  "100",
+		"listen_addresses": "*",
+		"shared_buffers":   "128MB",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMany() = %q, want %q", got, want)
+	}
+
+	for key, value := range got {
+		if s, err := c.StringK(key); err != nil || s != value {
+			t.Errorf("GetMany()[%q] = %q, but StringK(%q) = %q, %v", key, value, key, s, err)
+		}
+	}
+}
+
+// benchmarkKeys are the keys read in the benchmarks of GetMany and StringK.
+var benchmarkKeys = []string{"port", "max_connections", "shared_buffers", "work_mem", "wal_level", "fsync", "no_such_key"}
+
+func BenchmarkGetMany(b *testing.B) {
+	c := conf.New(strings.Repeat(readTestFile(b, "postgresql-default.conf"), 20))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.GetMany(benchmarkKeys...)
+	}
+}
+
+func BenchmarkStringK_Sequential(b *testing.B) {
+	c := conf.New(strings.Repeat(readTestFile(b, "postgresql-default.conf"), 20))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, key := range benchmarkKeys {
+			c.StringK(key)
+		}
+	}
+}
+
+func TestTidyBlankLines(t *testing.T) {
+	c := conf.New("# Connections\nport = 5432\n\n\n\n# Memory\nwork_mem = 4MB\n\n# Logging\n\n\n\nlog_connections = on\n")
+	if got := c.TidyBlankLines(); got != 4 {
+		t.Errorf("TidyBlankLines() = %d, want %d", got, 4)
+	}
+	want := "# Connections\nport = 5432\n\n# Memory\nwork_mem = 4MB\n\n# Logging\n\nlog_connections = on\n"
+	if got := c.All(); got != want {
+		t.Errorf("TidyBlankLines() changed configuration to %q, want %q", got, want)
+	}
+}
+
+func TestRequireUnique(t *testing.T) {
+	clean := conf.New("port = 5432\n# port = 5433\nwork_mem = 4MB\n")
+	if err := clean.RequireUnique(); err != nil {
+		t.Errorf("RequireUnique() errored with '%s', wanted no error", err)
+	}
+
+	c := conf.New("port = 5432\nwork_mem = 4MB\nPort = 5433\nfsync = on\nwork_mem = 8MB\nport = 5434\n")
+	wantDuplicates := map[string][]int{"port": {1, 3, 6}, "work_mem": {2, 5}}
+	if got := c.Duplicates(); !reflect.DeepEqual(got, wantDuplicates) {
+		t.Errorf("Duplicates() = %v, want %v", got, wantDuplicates)
+	}
+	err := c.RequireUnique()
+	if err == nil {
+		t.Fatalf("RequireUnique() did not error, wanted error")
+	}
+	if want := "keys set more than once: port on lines 1, 3, 6; work_mem on lines 2, 5"; err.Error() != want {
+		t.Errorf("RequireUnique() errored with '%s', want '%s'", err, want)
+	}
+}
+
+func TestDeleteK(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"Middle line", "port = 5432\n  work_mem = 4MB  # comment\nfsync = on\n", "work_mem", "port = 5432\nfsync = on\n", false},
+		{"Case insensitive", "port = 5432\nWork_Mem = 4MB\n", "WORK_MEM", "port = 5432\n", false},
+		{"Last effective occurrence", "port = 5432\nfsync = on\nport = 5433\n", "port", "port = 5432\nfsync = on\n", false},
+		{"Last line without EOL", "port = 5432\nfsync = on", "fsync", "port = 5432", false},
+		{"Last line without EOL after CRLF", "port = 5432\r\nfsync = on", "fsync", "port = 5432", false},
+		{"Only line without EOL", "fsync = on", "fsync", "", false},
+		{"Commented out", "#port = 5432\nfsync = on\n", "port", "#port = 5432\nfsync = on\n", true},
+		{"Missing key", "port = 5432\n", "fsync", "port = 5432\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(tt.content)
+			err := c.DeleteK(tt.key)
+			if tt.wantErr && err != generic.ErrKeyNotFound {
+				t.Errorf("DeleteK(%q) errored with '%v', want '%s'", tt.key, err, generic.ErrKeyNotFound)
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("DeleteK(%q) errored with '%s', wanted no error", tt.key, err)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("DeleteK(%q) changed configuration to %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOccurrenceLines(t *testing.T) {
+	c := conf.New("# Connections\n#port = 5432\t\t# default\nmax_connections = 100\nPORT = 6000\n# port is set above\n#max_connections = 50\n")
+
+	got, err := c.OccurrenceLines("port")
+	if err != nil {
+		t.Fatalf("OccurrenceLines(%q) errored with '%s', wanted no error", "port", err)
+	}
+	want := []conf.Occurrence{{Line: 2, Commented: true}, {Line: 4, Commented: false}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("OccurrenceLines(%q) = %v, want %v", "port", got, want)
+	}
+
+	got, _ = c.OccurrenceLines("max_connections")
+	want = []conf.Occurrence{{Line: 3, Commented: false}, {Line: 6, Commented: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("OccurrenceLines(%q) = %v, want %v", "max_connections", got, want)
+	}
+
+	if _, err := c.OccurrenceLines("work_mem"); err != generic.ErrKeyNotFound {
+		t.Errorf("OccurrenceLines(%q) errored with '%v', want '%s'", "work_mem", err, generic.ErrKeyNotFound)
+	}
+}
@@ -0,0 +1,75 @@
+package conf
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// categoryFiles maps top-level GUC categories to names of drop-in files used by SplitByCategory.
+var categoryFiles = map[string]string{
+	"File Locations":                 "00-file-locations.conf",
+	"Connections and Authentication": "10-connections.conf",
+	"Resource Usage":                 "20-resources.conf",
+	"Write-Ahead Log":                "30-wal.conf",
+	"Replication":                    "40-replication.conf",
+	"Query Tuning":                   "50-query-tuning.conf",
+	"Reporting and Logging":          "60-logging.conf",
+	"Autovacuum":                     "70-autovacuum.conf",
+	"Client Connection Defaults":     "80-client-defaults.conf",
+}
+
+// otherCategoryFile is the drop-in file for settings without a known category.
+const otherCategoryFile = "99-other.conf"
+
+// categoryFile returns the name of the drop-in file for the key.
+func categoryFile(key string) string {
+	g, ok := LookupGUC(key)
+	if !ok {
+		return otherCategoryFile
+	}
+	category := strings.SplitN(g.Category, " / ", 2)[0]
+	if name, ok := categoryFiles[category]; ok {
+		return name
+	}
+	return otherCategoryFile
+}
+
+// SplitByCategory writes the effective settings into drop-in files in dir (eg. 10-connections.conf,
+// 20-resources.conf), one file per GUC category, suitable for use with include_dir. Settings whose
+// value equals the documented default are skipped. Settings unknown to the GUC registry go into
+// 99-other.conf. No file is written for categories without settings. The directory is created if
+// it does not exist.
+func (c *Conf) SplitByCategory(dir string, perm os.FileMode) error {
+	files := make(map[string]*Conf)
+	var names []string
+	for _, s := range c.effectiveSettings() {
+		raw, err := c.Raw(s.row, valueCol)
+		if err != nil {
+			return err
+		}
+		if g, ok := LookupGUC(s.key); ok && strings.EqualFold(c.Dequote(raw), g.Default) {
+			continue
+		}
+
+		name := categoryFile(s.key)
+		f, ok := files[name]
+		if !ok {
+			f = New("")
+			files[name] = f
+			names = append(names, name)
+		}
+		if _, err := f.Append(s.key, raw); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		f := files[name]
+		f.EnsureEndsWithEOL()
+		if err := f.WriteFileMkdir(filepath.Join(dir, name), perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package conf_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestSplitByCategory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgconf")
+	if err != nil {
+		t.Fatalf("TempDir() failed: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := conf.New("# Connections\n" +
+		"port = 6000\n" +
+		"max_connections = 100\n" + // Default value, should be skipped
+		"listen_addresses = '*'\n" +
+		"work_mem = 4MB\n" +
+		"shared_buffers = 1GB\n" +
+		"work_mem = 16MB # Overrides previous value\n")
+	err = c.SplitByCategory(filepath.Join(dir, "conf.d"), 0644)
+	if err != nil {
+		t.Fatalf("SplitByCategory() errored with '%s', wanted no error", err)
+	}
+
+	want := map[string]string{
+		"10-connections.conf": "port = 6000\nlisten_addresses = '*'\n",
+		"20-resources.conf":   "shared_buffers = 1GB\nwork_mem = 16MB\n",
+	}
+	infos, err := ioutil.ReadDir(filepath.Join(dir, "conf.d"))
+	if err != nil {
+		t.Fatalf("ReadDir() failed: %s", err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	sort.Strings(names)
+	if len(names) != len(want) {
+		t.Fatalf("SplitByCategory() wrote files %q, want %d files", names, len(want))
+	}
+	for name, wantContent := range want {
+		got, err := ioutil.ReadFile(filepath.Join(dir, "conf.d", name))
+		if err != nil {
+			t.Errorf("SplitByCategory() did not write %s: %s", name, err)
+		} else if string(got) != wantContent {
+			t.Errorf("SplitByCategory() wrote %q to %s, want %q", got, name, wantContent)
+		}
+	}
+}
+
+func TestSplitByCategory_Other(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgconf")
+	if err != nil {
+		t.Fatalf("TempDir() failed: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := conf.New("my.custom_setting = 'x'\n")
+	if err := c.SplitByCategory(dir, 0644); err != nil {
+		t.Fatalf("SplitByCategory() errored with '%s', wanted no error", err)
+	}
+	got, err := ioutil.ReadFile(filepath.Join(dir, "99-other.conf"))
+	if err != nil || string(got) != "my.custom_setting = 'x'\n" {
+		t.Errorf("SplitByCategory() wrote %q to 99-other.conf (err: %v), want %q", got, err, "my.custom_setting = 'x'\n")
+	}
+}
@@ -0,0 +1,55 @@
+package conf
+
+import (
+	"strings"
+
+	"github.com/quasoft/pgconf/generic"
+)
+
+// Stats holds counts of the different kinds of lines in a configuration file.
+type Stats struct {
+	Lines             int // Total number of lines
+	Settings          int // Lines with an active key and value
+	CommentedSettings int // Comment lines holding a disabled setting (eg. #port = 5432)
+	Comments          int // Comment-only lines, other than commented out settings
+	Blank             int // Empty or whitespace-only lines
+	KeysWithoutValue  int // Lines with a key, but no value (ignored by PostgreSQL)
+	DuplicateKeys     int // Number of keys that are set on more than one line
+}
+
+// Stats returns counts of the different kinds of lines in the configuration, computed in a single pass.
+func (c *Conf) Stats() Stats {
+	var stats Stats
+	occurrences := make(map[string]int)
+	for _, line := range c.Lines() {
+		stats.Lines++
+
+		row, err := c.RowOf(line)
+		if err == generic.ErrEmptyLine {
+			if strings.TrimSpace(line.Text) == "" {
+				stats.Blank++
+			} else if _, ok := c.commentedSettingRow(line); ok {
+				stats.CommentedSettings++
+			} else {
+				stats.Comments++
+			}
+			continue
+		} else if err != nil {
+			continue
+		}
+
+		if !row.HasColumn(valueCol) {
+			stats.KeysWithoutValue++
+			continue
+		}
+		stats.Settings++
+		if key, err := c.Raw(row, keyCol); err == nil {
+			key = NormalizeKey(key)
+			occurrences[key]++
+			if occurrences[key] == 2 {
+				stats.DuplicateKeys++
+			}
+		}
+	}
+	return stats
+}
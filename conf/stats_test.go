@@ -0,0 +1,44 @@
+package conf_test
+
+import (
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestStats(t *testing.T) {
+	tests := []struct {
+		name string
+		conf *conf.Conf
+		want conf.Stats
+	}{
+		{
+			"Sample file",
+			openConfFile(t),
+			conf.Stats{Lines: 37, Settings: 19, CommentedSettings: 0, Comments: 6, Blank: 9, KeysWithoutValue: 3, DuplicateKeys: 0},
+		},
+		{
+			"Default style file",
+			openTestFile(t, "postgresql-default.conf"),
+			conf.Stats{Lines: 49, Settings: 5, CommentedSettings: 7, Comments: 27, Blank: 10, KeysWithoutValue: 0, DuplicateKeys: 0},
+		},
+		{
+			"Duplicates",
+			conf.New("port = 1\n\nport = 2\nPORT = 3\nwork_mem = 1MB\nwork_mem = 2MB\n#port = 4\n"),
+			conf.Stats{Lines: 7, Settings: 5, CommentedSettings: 1, Comments: 0, Blank: 1, KeysWithoutValue: 0, DuplicateKeys: 2},
+		},
+		{
+			"Empty",
+			conf.New(""),
+			conf.Stats{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.conf.Stats()
+			if got != tt.want {
+				t.Errorf("Stats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
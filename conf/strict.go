@@ -0,0 +1,64 @@
+package conf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SetStrictValidation enables or disables validation of values against the GUC registry when
+// setting values. When enabled, setters return an error if a string value is longer than the
+// documented maximum length of the parameter, or if a numeric value is outside of the documented
+// range of the parameter. Parameters unknown to the registry are never validated.
+// Validation is disabled by default.
+func (c *Conf) SetStrictValidation(strict bool) {
+	c.strict = strict
+}
+
+// validateK validates the dequoted value for the given key if strict validation is enabled.
+func (c *Conf) validateK(key string, value string) error {
+	if !c.strict {
+		return nil
+	}
+	g, ok := LookupGUC(key)
+	if !ok {
+		return nil
+	}
+	return g.Validate(value)
+}
+
+// Validate checks if the dequoted value is within the documented limits of the parameter.
+// Integer values with a unit suffix (eg. 128MB) are not range checked. Numeric values with a comma
+// (eg. 0,5) are rejected, as PostgreSQL accepts only a period as the decimal separator.
+func (g GUC) Validate(value string) error {
+	value = strings.TrimSpace(value)
+	if (g.Type == IntGUC || g.Type == RealGUC) && strings.ContainsRune(value, ',') {
+		return fmt.Errorf("value %s for %s contains a comma, want a period as the decimal separator", value, g.Name)
+	}
+	switch g.Type {
+	case StringGUC:
+		if g.MaxLength > 0 && len(value) > g.MaxLength {
+			return fmt.Errorf("value for %s is %d bytes long, want at most %d", g.Name, len(value), g.MaxLength)
+		}
+	case IntGUC:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			return fmt.Errorf("value %s for %s overflows integer type", value, g.Name)
+		} else if err != nil {
+			// Probably a value with a unit (eg. 128MB)
+			return nil
+		}
+		if float64(n) < g.Min || float64(n) > g.Max {
+			return fmt.Errorf("value %d for %s is outside the valid range %.0f to %.0f", n, g.Name, g.Min, g.Max)
+		}
+	case RealGUC:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("value %s for %s is not a floating point number", value, g.Name)
+		}
+		if f < g.Min || f > g.Max {
+			return fmt.Errorf("value %v for %s is outside the valid range %v to %v", f, g.Name, g.Min, g.Max)
+		}
+	}
+	return nil
+}
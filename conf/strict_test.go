@@ -0,0 +1,80 @@
+package conf_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestSetStrictValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		strict  bool
+		set     func(c *conf.Conf) error
+		noerror bool
+	}{
+		{"Int above max", true, func(c *conf.Conf) error { return c.SetIntK("port", 70000) }, false},
+		{"Int below min", true, func(c *conf.Conf) error { return c.SetIntK("max_connections", 0) }, false},
+		{"Int64 above max", true, func(c *conf.Conf) error { return c.SetInt64K("autovacuum_freeze_max_age", 4000000000) }, false},
+		{"Raw int overflowing int64", true, func(c *conf.Conf) error { return c.SetRawK("port", "99999999999999999999") }, false},
+		{"Float above max", true, func(c *conf.Conf) error { return c.SetFloat64K("checkpoint_completion_target", 1.5) }, false},
+		{"String too long", true, func(c *conf.Conf) error { return c.SetStringK("cluster_name", strings.Repeat("x", 64)) }, false},
+		{"Float with comma", true, func(c *conf.Conf) error { return c.SetRawK("checkpoint_completion_target", "0,9") }, false},
+		{"Quoted float with comma", true, func(c *conf.Conf) error { return c.SetRawK("random_page_cost", "'1,1'") }, false},
+		{"Int with comma and unit", true, func(c *conf.Conf) error { return c.SetRawK("work_mem", "1,5MB") }, false},
+		{"Float with comma not strict", false, func(c *conf.Conf) error { return c.SetRawK("checkpoint_completion_target", "0,9") }, true},
+		{"String with comma", true, func(c *conf.Conf) error { return c.SetStringK("listen_addresses", "localhost,10.0.0.1") }, true},
+		{"Int in range", true, func(c *conf.Conf) error { return c.SetIntK("port", 6000) }, true},
+		{"Value with unit", true, func(c *conf.Conf) error { return c.SetRawK("shared_buffers", "256MB") }, true},
+		{"Unknown key", true, func(c *conf.Conf) error { return c.SetIntK("my.custom_setting", 70000) }, true},
+		{"Not strict", false, func(c *conf.Conf) error { return c.SetIntK("port", 70000) }, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New("port = 5432\n")
+			c.SetStrictValidation(tt.strict)
+			err := tt.set(c)
+			if err != nil && tt.noerror {
+				t.Errorf("Set errored with '%s', wanted no error", err)
+			} else if err == nil && !tt.noerror {
+				t.Errorf("Set did not error, wanted error")
+			} else if err != nil && c.All() != "port = 5432\n" {
+				t.Errorf("Set errored, but modified configuration to %q", c.All())
+			}
+		})
+	}
+}
+
+func TestSetFloat64K_DecimalSeparator(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  string
+	}{
+		{0.9, "checkpoint_completion_target = 0.9"},
+		{1.5, "checkpoint_completion_target = 1.5"},
+		{0.0000001, "checkpoint_completion_target = 0.0000001"},
+		{1234567.25, "checkpoint_completion_target = 1234567.25"},
+	}
+	for _, tt := range tests {
+		c := conf.New("")
+		if err := c.SetFloat64K("checkpoint_completion_target", tt.value); err != nil {
+			t.Fatalf("SetFloat64K(%v) errored with '%s', wanted no error", tt.value, err)
+		}
+		if got := c.All(); got != tt.want {
+			t.Errorf("SetFloat64K(%v) changed configuration to %q, want %q", tt.value, got, tt.want)
+		}
+		if strings.ContainsRune(c.All(), ',') {
+			t.Errorf("SetFloat64K(%v) wrote a comma: %q", tt.value, c.All())
+		}
+	}
+
+	c := conf.New("")
+	c.SetStrictValidation(true)
+	if err := c.SetFloat64SciK("cpu_operator_cost", 0.0025, 2); err != nil {
+		t.Fatalf("SetFloat64SciK() errored with '%s', wanted no error", err)
+	}
+	if got, want := c.All(), "cpu_operator_cost = 2.50e-03"; got != want {
+		t.Errorf("SetFloat64SciK() changed configuration to %q, want %q", got, want)
+	}
+}
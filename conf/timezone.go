@@ -0,0 +1,22 @@
+package conf
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetTimezoneK replaces the value of a time zone setting (eg. timezone or log_timezone), enclosing
+// it in quotes, after validating the zone name against the time zone database (see
+// time.LoadLocation). Returns an error and leaves the configuration unchanged if the zone is
+// unknown.
+func (c *Conf) SetTimezoneK(key, tz string) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetTimezoneK", key)(&err)
+	if tz == "" || tz == "Local" {
+		return fmt.Errorf("invalid time zone %q for key %s", tz, key)
+	}
+	if _, err := time.LoadLocation(tz); err != nil {
+		return fmt.Errorf("invalid time zone %q for key %s: %s", tz, key, err)
+	}
+	return c.SetStringK(key, tz)
+}
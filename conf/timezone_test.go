@@ -0,0 +1,35 @@
+package conf_test
+
+import (
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestSetTimezoneK(t *testing.T) {
+	tests := []struct {
+		name    string
+		tz      string
+		want    string
+		wantErr bool
+	}{
+		{"Valid zone", "America/New_York", "timezone = 'America/New_York'\nport = 5432\n", false},
+		{"UTC", "UTC", "timezone = 'UTC'\nport = 5432\n", false},
+		{"Unknown zone", "Not/AZone", "timezone = 'UTC'\nport = 5432\n", true},
+		{"Empty zone", "", "timezone = 'UTC'\nport = 5432\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New("timezone = 'UTC'\nport = 5432\n")
+			err := c.SetTimezoneK("timezone", tt.tz)
+			if tt.wantErr && err == nil {
+				t.Errorf("SetTimezoneK(%q) did not error, wanted error", tt.tz)
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("SetTimezoneK(%q) errored with '%s', wanted no error", tt.tz, err)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("SetTimezoneK(%q) changed configuration to %q, want %q", tt.tz, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package conf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/quasoft/pgconf/generic"
+)
+
+// memoryUnits maps the memory units accepted by PostgreSQL to their size in bytes.
+var memoryUnits = map[string]int64{
+	"B":  1,
+	"kB": 1 << 10,
+	"MB": 1 << 20,
+	"GB": 1 << 30,
+	"TB": 1 << 40,
+}
+
+// memoryUnitNames lists the memory units from the largest to the smallest.
+var memoryUnitNames = []string{"TB", "GB", "MB", "kB", "B"}
+
+// unitSize returns the size in bytes of a memory unit, optionally prefixed with a multiplier
+// (eg. 8kB, as used by the unit of shared_buffers). If lenient is true, the case of the unit is
+// ignored. The second return value is false if the unit is not a memory unit.
+func unitSize(unit string, lenient bool) (int64, bool) {
+	digits := len(unit) - len(strings.TrimLeft(unit, "0123456789"))
+	multiplier := int64(1)
+	if digits > 0 {
+		n, err := strconv.ParseInt(unit[:digits], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		multiplier = n
+	}
+	name := unit[digits:]
+	for _, u := range memoryUnitNames {
+		if name == u || (lenient && strings.EqualFold(name, u)) {
+			return multiplier * memoryUnits[u], true
+		}
+	}
+	return 0, false
+}
+
+// parseBytes parses a memory value like 128MB into a number of bytes. Values without a unit are
+// in defaultUnit (eg. 8kB for shared_buffers), or in bytes if defaultUnit is empty. If lenient is
+// true, the case of units is ignored.
+func parseBytes(value string, defaultUnit string, lenient bool) (int64, error) {
+	value = strings.TrimSpace(value)
+	digits := len(value) - len(strings.TrimLeft(value, "+-0123456789"))
+	n, err := strconv.ParseInt(value[:digits], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory value %q", value)
+	}
+	unit := strings.TrimSpace(value[digits:])
+	if unit == "" {
+		unit = defaultUnit
+	}
+	size := int64(1)
+	if unit != "" {
+		var ok bool
+		if size, ok = unitSize(unit, lenient); !ok {
+			return 0, fmt.Errorf("invalid memory unit %q in value %q, want one of B, kB, MB, GB or TB", unit, value)
+		}
+	}
+	bytes := n * size
+	if n != 0 && bytes/size != n {
+		return 0, fmt.Errorf("memory value %q is out of range", value)
+	}
+	return bytes, nil
+}
+
+// defaultMemoryUnit returns the implicit unit of unitless values of the key, according to the
+// GUC registry. Returns an error if the key is known, but is not a memory setting.
+func defaultMemoryUnit(key string) (string, error) {
+	g, ok := LookupGUC(key)
+	if !ok || g.Unit == "" {
+		return "", nil
+	}
+	if _, ok := unitSize(g.Unit, false); !ok {
+		return "", fmt.Errorf("%s is not a memory setting", key)
+	}
+	return g.Unit, nil
+}
+
+// AsBytesK retrieves the value of a memory setting (eg. 128MB) as a number of bytes.
+// Units are case sensitive, as in PostgreSQL. Unitless values are interpreted in the implicit unit
+// of the setting from the GUC registry (eg. 8kB pages for shared_buffers), or as bytes for
+// settings unknown to the registry.
+func (c *Conf) AsBytesK(key string) (int64, error) {
+	value, err := c.StringK(key) // Read as string first to dequote the value
+	if err != nil {
+		return 0, err
+	}
+	unit, err := defaultMemoryUnit(c.resolveAliasK(key))
+	if err != nil {
+		return 0, err
+	}
+	n, err := parseBytes(value, unit, false)
+	return n, parseError(key, value, err)
+}
+
+// AsBytesLenientK retrieves the value of a memory setting as a number of bytes like AsBytesK,
+// but ignores the case of units (eg. 128mb is read as 128MB). This is more permissive than
+// PostgreSQL itself, which rejects such values, and is meant for diagnosing or migrating
+// hand-edited files. Use AsBytesK to read values the way PostgreSQL does.
+func (c *Conf) AsBytesLenientK(key string) (int64, error) {
+	value, err := c.StringK(key) // Read as string first to dequote the value
+	if err != nil {
+		return 0, err
+	}
+	unit, err := defaultMemoryUnit(c.resolveAliasK(key))
+	if err != nil {
+		return 0, err
+	}
+	n, err := parseBytes(value, unit, true)
+	return n, parseError(key, value, err)
+}
+
+// formatBytes formats the number of bytes with the largest memory unit that represents it exactly
+// (eg. 256MB or 1536kB).
+func formatBytes(bytes int64) string {
+	if bytes == 0 {
+		return "0"
+	}
+	for _, u := range memoryUnitNames {
+		if bytes%memoryUnits[u] == 0 {
+			return strconv.FormatInt(bytes/memoryUnits[u], 10) + u
+		}
+	}
+	return strconv.FormatInt(bytes, 10) + "B"
+}
+
+// SetBytesK replaces the value of a memory setting with the number of bytes, written unquoted
+// with the largest unit that represents it exactly (eg. 268435456 is written as 256MB).
+func (c *Conf) SetBytesK(key string, bytes int64) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetBytesK", key)(&err)
+	if _, err := defaultMemoryUnit(key); err != nil {
+		return err
+	}
+	return c.SetRawK(key, formatBytes(bytes))
+}
+
+// SetBytesKBounded replaces the value of a memory setting like SetBytesK, unless the new value is
+// more than maxFactor times larger or smaller than the current value (eg. with a maxFactor of 3,
+// 128MB can be changed to anything from 43MB to 384MB), in which case an error is returned and
+// the configuration is left unchanged. This guards against runaway changes (eg. by autotuning).
+// The check is skipped if the key is not set or its current value is 0.
+func (c *Conf) SetBytesKBounded(key string, bytes int64, maxFactor float64) (err error) {
+	defer c.aliasScope(&key)()
+	defer c.logChangeK("SetBytesKBounded", key)(&err)
+	if maxFactor < 1 {
+		return fmt.Errorf("invalid factor %g, want a factor of at least 1", maxFactor)
+	}
+	current, err := c.AsBytesK(key)
+	if err != nil && err != generic.ErrKeyNotFound {
+		return err
+	}
+	if err == nil && current != 0 {
+		ratio := float64(bytes) / float64(current)
+		if ratio > maxFactor || ratio < 1/maxFactor {
+			return fmt.Errorf(
+				"new value %s for %s differs from current value %s by more than a factor of %g",
+				formatBytes(bytes), key, formatBytes(current), maxFactor,
+			)
+		}
+	}
+	return c.SetBytesK(key, bytes)
+}
+
+// AsStringSliceK retrieves the value of the key as a comma-separated list of strings
+// (eg. 'localhost, 10.0.0.1'), with whitespace around elements removed. Empty elements are skipped.
+func (c *Conf) AsStringSliceK(key string) ([]string, error) {
+	value, err := c.StringK(key) // Read as string first to dequote the value
+	if err != nil {
+		return nil, err
+	}
+	var result []string
+	for _, elem := range strings.Split(value, ",") {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			result = append(result, elem)
+		}
+	}
+	return result, nil
+}
+
+// SetListenAddresses replaces the value of listen_addresses with the comma-separated list of
+// host names and IP addresses (eg. 'localhost,10.0.0.1'). An empty list disables TCP/IP connections.
+func (c *Conf) SetListenAddresses(addrs ...string) (err error) {
+	defer c.logChangeK("SetListenAddresses", "listen_addresses")(&err)
+	return c.SetStringK("listen_addresses", strings.Join(addrs, ","))
+}
@@ -0,0 +1,150 @@
+package conf_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestAsBytesK(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		want    int64
+		wantErr bool
+	}{
+		{"Megabytes", "shared_buffers = 128MB", "shared_buffers", 128 << 20, false},
+		{"Quoted with space", "work_mem = '64 kB'", "work_mem", 64 << 10, false},
+		{"Unitless in pages", "shared_buffers = 16384", "shared_buffers", 16384 * 8192, false},
+		{"Unitless in kB", "work_mem = 4096", "work_mem", 4096 << 10, false},
+		{"Unitless unknown key", "my.buffer = 100", "my.buffer", 100, false},
+		{"Terabytes", "effective_cache_size = 1TB", "effective_cache_size", 1 << 40, false},
+		{"Wrong case", "shared_buffers = 128mb", "shared_buffers", 0, true},
+		{"Not a memory setting", "checkpoint_timeout = 5min", "checkpoint_timeout", 0, true},
+		{"Overflow", "my.buffer = 9000000TB", "my.buffer", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := conf.New(tt.content).AsBytesK(tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("AsBytesK(%q) = %d, did not error, wanted error", tt.key, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AsBytesK(%q) errored with '%s', wanted no error", tt.key, err)
+			}
+			if got != tt.want {
+				t.Errorf("AsBytesK(%q) = %d, want %d", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsBytesLenientK(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		want    int64
+		wantErr bool
+	}{
+		{"Lowercase megabytes", "shared_buffers = 128mb", "shared_buffers", 128 << 20, false},
+		{"Lowercase gigabytes", "effective_cache_size = '8gb'", "effective_cache_size", 8 << 30, false},
+		{"Uppercase kilobytes", "work_mem = 64KB", "work_mem", 64 << 10, false},
+		{"Exact case", "work_mem = 4MB", "work_mem", 4 << 20, false},
+		{"Unitless in pages", "shared_buffers = 16384", "shared_buffers", 16384 * 8192, false},
+		{"Unknown unit", "work_mem = 4mib", "work_mem", 0, true},
+		{"Not a memory setting", "checkpoint_timeout = 5min", "checkpoint_timeout", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := conf.New(tt.content).AsBytesLenientK(tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("AsBytesLenientK(%q) = %d, did not error, wanted error", tt.key, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AsBytesLenientK(%q) errored with '%s', wanted no error", tt.key, err)
+			}
+			if got != tt.want {
+				t.Errorf("AsBytesLenientK(%q) = %d, want %d", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAsStringSliceK(t *testing.T) {
+	c := conf.New("listen_addresses = 'localhost, 10.0.0.1,,'\n")
+	got, err := c.AsStringSliceK("listen_addresses")
+	if err != nil {
+		t.Fatalf("AsStringSliceK() errored with '%s', wanted no error", err)
+	}
+	if want := []string{"localhost", "10.0.0.1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AsStringSliceK() = %q, want %q", got, want)
+	}
+}
+
+func TestSetBytesK(t *testing.T) {
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "0"},
+		{256 << 20, "256MB"},
+		{1536 << 10, "1536kB"},
+		{2 << 40, "2TB"},
+		{1000, "1000B"},
+	}
+	for _, tt := range tests {
+		c := conf.New("work_mem = 4MB\n")
+		if err := c.SetBytesK("work_mem", tt.bytes); err != nil {
+			t.Fatalf("SetBytesK(%d) errored with '%s', wanted no error", tt.bytes, err)
+		}
+		if got, _ := c.RawK("work_mem"); got != tt.want {
+			t.Errorf("SetBytesK(%d) wrote %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+
+	if err := conf.New("").SetBytesK("checkpoint_timeout", 1024); err == nil {
+		t.Errorf("SetBytesK() on a time setting did not error, wanted error")
+	}
+}
+
+func TestSetBytesKBounded(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		bytes     int64
+		maxFactor float64
+		want      string
+		wantErr   bool
+	}{
+		{"Double within 3x", "shared_buffers = 128MB\n", 256 << 20, 3, "shared_buffers = 256MB\n", false},
+		{"Half within 3x", "shared_buffers = 128MB\n", 64 << 20, 3, "shared_buffers = 64MB\n", false},
+		{"Exactly 3x", "shared_buffers = 128MB\n", 384 << 20, 3, "shared_buffers = 384MB\n", false},
+		{"Tenfold", "shared_buffers = 128MB\n", 1280 << 20, 3, "shared_buffers = 128MB\n", true},
+		{"Tenth", "shared_buffers = 128MB\n", 128 << 20 / 10, 3, "shared_buffers = 128MB\n", true},
+		{"Missing key", "port = 5432\n", 8 << 30, 3, "port = 5432\nshared_buffers = 8GB", false},
+		{"Invalid factor", "shared_buffers = 128MB\n", 128 << 20, 0.5, "shared_buffers = 128MB\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New(tt.content)
+			err := c.SetBytesKBounded("shared_buffers", tt.bytes, tt.maxFactor)
+			if tt.wantErr && err == nil {
+				t.Errorf("SetBytesKBounded(%d, %g) did not error, wanted error", tt.bytes, tt.maxFactor)
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("SetBytesKBounded(%d, %g) errored with '%s', wanted no error", tt.bytes, tt.maxFactor, err)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("SetBytesKBounded(%d, %g) changed configuration to %q, want %q", tt.bytes, tt.maxFactor, got, tt.want)
+			}
+		})
+	}
+}
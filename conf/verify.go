@@ -0,0 +1,57 @@
+package conf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Verify re-parses the whole configuration and checks that every line that is not blank or
+// a comment contains either a valid key and value, or a key without value. Lines with an invalid
+// key, an unterminated quoted value or unexpected text after a quoted value are reported in the
+// returned error, along with their line numbers. Returns nil if all lines are valid.
+// Useful as a cheap integrity check after a series of edits.
+func (c *Conf) Verify() error {
+	var problems []string
+	for _, line := range c.Lines() {
+		row, err := c.RowOf(line)
+		if err != nil {
+			continue // Blank or comment-only line
+		}
+		var problem string
+		key, _ := c.Raw(row, keyCol)
+		if !keyPattern.MatchString(key) {
+			problem = fmt.Sprintf("invalid key %q", key)
+		} else if value, err := c.Raw(row, valueCol); err == nil && !c.isTerminated(value) {
+			problem = fmt.Sprintf("unterminated quoted value %s", value)
+		} else if row.ColCount() > valueCol+1 && c.joinUnquotedValue(row).ColCount() > valueCol+1 {
+			problem = "unexpected text after quoted value"
+		}
+		if problem != "" {
+			problems = append(problems, fmt.Sprintf("line %d: %s", line.Number, problem))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("configuration has invalid lines: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// isTerminated tests if a raw value that starts with a quote character also ends with the same one.
+func (c *Conf) isTerminated(value string) bool {
+	quotes := c.Params().Quotes
+	first := value[:1]
+	if !strings.Contains(quotes, first) {
+		return true
+	}
+	return len(value) > 1 && strings.HasSuffix(value, first) && !c.endsWithEscape(value[:len(value)-1])
+}
+
+// endsWithEscape tests if the value ends with an odd number of backslashes, that would escape a
+// character following the value.
+func (c *Conf) endsWithEscape(value string) bool {
+	if !c.Params().BackslashEscapedQuotes {
+		return false
+	}
+	n := len(value) - len(strings.TrimRight(value, `\`))
+	return n%2 == 1
+}
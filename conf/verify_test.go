@@ -0,0 +1,40 @@
+package conf_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantErr  bool
+		wantLine string
+	}{
+		{"Valid", "# comment\n\nport = 5432 # inline\nlog_connections\napplication_name = 'it''s'\npath = 'c:\\\\'\nlog_line_prefix = %m [%p] \n", false, ""},
+		{"Unterminated quote", "port = 5432\n\nlisten_addresses = '*\n", true, "line 3"},
+		{"Escaped closing quote", "port = 5432\napplication_name = 'app\\'\n", true, "line 2"},
+		{"Invalid key", "port = 5432\n'port' = 5433\n", true, "line 2"},
+		{"Text after quoted value", "application_name = 'app' extra\n", true, "line 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := conf.New(tt.content).Verify()
+			if !tt.wantErr {
+				if err != nil {
+					t.Errorf("Verify() errored with '%s', wanted no error", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Verify() did not error, wanted error")
+			}
+			if !strings.Contains(err.Error(), tt.wantLine+":") {
+				t.Errorf("Verify() = '%s', want error mentioning %s", err, tt.wantLine)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package confyaml
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/quasoft/pgconf/conf"
+)
+
+// intPattern and floatPattern match values written as YAML numbers.
+var (
+	intPattern   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	floatPattern = regexp.MustCompile(`^[-+]?([0-9]+\.[0-9]*|\.[0-9]+)([eE][-+]?[0-9]+)?$`)
+)
+
+// boolValues maps the boolean words accepted by both PostgreSQL and YAML to their value.
+var boolValues = map[string]bool{
+	"on": true, "off": false,
+	"true": true, "false": false,
+	"yes": true, "no": false,
+}
+
+// ToYAML returns the effective settings of the configuration as a flat YAML map, in file order.
+// Values that look like integers and floating point numbers are written as YAML numbers, boolean
+// words (on/off, true/false and yes/no) as YAML booleans, while all other values (including values
+// with units, like 128MB) are written as double quoted strings.
+func ToYAML(c *conf.Conf) ([]byte, error) {
+	var b bytes.Buffer
+	for _, p := range c.OrderedPairs() {
+		key, value := p[0], strings.TrimSpace(p[1])
+		if !keyPattern.MatchString(key) {
+			return nil, fmt.Errorf("could not write key %q to YAML", key)
+		}
+		if bv, ok := boolValues[strings.ToLower(value)]; ok {
+			value = strconv.FormatBool(bv)
+		} else if !intPattern.MatchString(value) && !floatPattern.MatchString(value) {
+			value = strconv.Quote(p[1])
+		}
+		b.WriteString(key + ": " + value + "\n")
+	}
+	return b.Bytes(), nil
+}
+
+// keyPattern matches parameter names, including custom (namespaced) parameters.
+var keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// FromYAML applies the settings of a flat YAML map (eg. as written by ToYAML) to the configuration,
+// updating existing keys and appending missing ones, in the order of the map. Numbers are written
+// unquoted, booleans as on or off, and all other values as quoted strings.
+// Only a subset of YAML is supported: a single document with a map of parameter names to plain,
+// single quoted or double quoted scalars, and comments. Nested maps, lists and multi-line values
+// are reported as errors. All lines are parsed before any setting is applied, so that an error
+// leaves the configuration unchanged.
+func FromYAML(c *conf.Conf, data []byte) error {
+	type entry struct {
+		key   string
+		value string
+		kind  int
+	}
+	const (
+		kindString = iota
+		kindNumber
+		kindBool
+	)
+
+	var entries []entry
+	for i, line := range strings.Split(string(data), "\n") {
+		number := i + 1
+		line = strings.TrimRight(line, " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" {
+			continue
+		}
+		if trimmed != line[:len(trimmed)] || strings.HasPrefix(trimmed, "- ") {
+			return fmt.Errorf("line %d: nested values are not supported", number)
+		}
+
+		colon := strings.Index(line, ":")
+		if colon == -1 {
+			return fmt.Errorf("line %d: want a key: value pair", number)
+		}
+		key := strings.TrimSpace(line[:colon])
+		if !keyPattern.MatchString(key) {
+			return fmt.Errorf("line %d: invalid parameter name %q", number, key)
+		}
+		raw := strings.TrimSpace(line[colon+1:])
+		if raw == "" {
+			return fmt.Errorf("line %d: key %s has no value", number, key)
+		}
+
+		var e entry
+		var err error
+		switch raw[0] {
+		case '"':
+			e.value, err = unquoteDouble(raw)
+		case '\'':
+			e.value, err = unquoteSingle(raw)
+		default:
+			e.value = stripComment(raw)
+			if b, ok := boolValues[strings.ToLower(e.value)]; ok {
+				e.kind = kindBool
+				e.value = strconv.FormatBool(b)
+			} else if intPattern.MatchString(e.value) || floatPattern.MatchString(e.value) {
+				e.kind = kindNumber
+			} else if strings.ContainsAny(e.value[:1], "[]{}&*!|>%@`") {
+				err = fmt.Errorf("value %q is not a scalar", e.value)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %s", number, err)
+		}
+		e.key = key
+		entries = append(entries, e)
+	}
+
+	for _, e := range entries {
+		var err error
+		switch e.kind {
+		case kindNumber:
+			err = c.SetRawK(e.key, e.value)
+		case kindBool:
+			err = c.SetOnOffK(e.key, e.value == "true")
+		default:
+			err = c.SetStringK(e.key, e.value)
+		}
+		if err != nil {
+			return fmt.Errorf("could not set %s: %s", e.key, err)
+		}
+	}
+	return nil
+}
+
+// unquoteDouble unquotes a double quoted YAML scalar, optionally followed by a comment.
+func unquoteDouble(raw string) (string, error) {
+	for end := 1; end < len(raw); end++ {
+		if raw[end] == '\\' {
+			end++
+			continue
+		}
+		if raw[end] != '"' {
+			continue
+		}
+		if rest := strings.TrimSpace(raw[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected text %q after quoted value", rest)
+		}
+		return strconv.Unquote(raw[:end+1])
+	}
+	return "", fmt.Errorf("unterminated quoted value %s", raw)
+}
+
+// unquoteSingle unquotes a single quoted YAML scalar, in which quotes are escaped by doubling
+// them, optionally followed by a comment.
+func unquoteSingle(raw string) (string, error) {
+	var b strings.Builder
+	for end := 1; end < len(raw); end++ {
+		if raw[end] != '\'' {
+			b.WriteByte(raw[end])
+			continue
+		}
+		if end+1 < len(raw) && raw[end+1] == '\'' {
+			b.WriteByte('\'')
+			end++
+			continue
+		}
+		if rest := strings.TrimSpace(raw[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected text %q after quoted value", rest)
+		}
+		return b.String(), nil
+	}
+	return "", fmt.Errorf("unterminated quoted value %s", raw)
+}
+
+// stripComment removes a comment (starting with # after whitespace) from a plain scalar.
+func stripComment(raw string) string {
+	for i := 1; i < len(raw); i++ {
+		if raw[i] == '#' && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+			return strings.TrimSpace(raw[:i])
+		}
+	}
+	return raw
+}
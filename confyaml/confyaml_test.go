@@ -0,0 +1,78 @@
+package confyaml_test
+
+import (
+	"testing"
+
+	"github.com/quasoft/pgconf/conf"
+	"github.com/quasoft/pgconf/confyaml"
+)
+
+func TestToYAML(t *testing.T) {
+	c := conf.New("port = 5432\nfsync = off\ncheckpoint_completion_target = 0.9\n" +
+		"shared_buffers = 128MB\nsearch_path = '\"$user\", public'\nport = 5433\n")
+	want := "fsync: false\n" +
+		"checkpoint_completion_target: 0.9\n" +
+		"shared_buffers: \"128MB\"\n" +
+		"search_path: \"\\\"$user\\\", public\"\n" +
+		"port: 5433\n"
+
+	got, err := confyaml.ToYAML(c)
+	if err != nil {
+		t.Fatalf("ToYAML() errored with '%s', wanted no error", err)
+	}
+	if string(got) != want {
+		t.Errorf("ToYAML() = %q, want %q", got, want)
+	}
+}
+
+func TestFromYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		want    string
+		wantErr bool
+	}{
+		{
+			"Typed values",
+			"---\n# Tuning\nport: 6000\nfsync: yes   # careful\nwork_mem: '64MB'\nlog_line_prefix: \"%m [%p] \"\nrandom_page_cost: 1.1\n",
+			"port = 6000\nfsync = on\nwork_mem = '64MB'\nlog_line_prefix = '%m [%p] '\nrandom_page_cost = 1.1",
+			false,
+		},
+		{"Plain string", "wal_level: logical\n", "port = 5432\nwal_level = 'logical'", false},
+		{"Nested map", "port: 6000\nlogging:\n  level: info\n", "port = 5432\n", true},
+		{"List", "listen_addresses: [localhost, 10.0.0.1]\n", "port = 5432\n", true},
+		{"Unterminated quote", "work_mem: '64MB\n", "port = 5432\n", true},
+		{"Missing value", "port:\n", "port = 5432\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conf.New("port = 5432\n")
+			err := confyaml.FromYAML(c, []byte(tt.yaml))
+			if tt.wantErr && err == nil {
+				t.Errorf("FromYAML() did not error, wanted error")
+			} else if !tt.wantErr && err != nil {
+				t.Errorf("FromYAML() errored with '%s', wanted no error", err)
+			}
+			if got := c.All(); got != tt.want {
+				t.Errorf("FromYAML() changed configuration to %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestYAML_RoundTrip(t *testing.T) {
+	c := conf.New("listen_addresses = 'localhost, 10.0.0.1'\nport = 5432\nssl = on\n" +
+		"shared_buffers = 1GB\ncpu_tuple_cost = 0.01\napplication_name = 'it''s \\\\ here'\n")
+	data, err := confyaml.ToYAML(c)
+	if err != nil {
+		t.Fatalf("ToYAML() errored with '%s', wanted no error", err)
+	}
+
+	restored := conf.New("")
+	if err := confyaml.FromYAML(restored, data); err != nil {
+		t.Fatalf("FromYAML(%q) errored with '%s', wanted no error", data, err)
+	}
+	if !conf.Equal(c, restored) {
+		t.Errorf("FromYAML(ToYAML()) = %q, want settings equal to %q", restored.All(), c.All())
+	}
+}
@@ -0,0 +1,77 @@
+package generic
+
+import (
+	"bufio"
+	"strings"
+)
+
+// Format identifies the kind of PostgreSQL configuration file.
+type Format int
+
+// Constants for the recognized configuration file formats
+const (
+	Unknown        Format = iota // Format could not be determined
+	PostgreSQLConf               // postgresql.conf style (key = value)
+	HBAConf                      // pg_hba.conf style (whitespace delimited columns)
+)
+
+// detectSampleSize is the maximum number of non-empty lines inspected by DetectFormat.
+const detectSampleSize = 200
+
+// hbaConnTypes lists the connection type keywords that start a pg_hba.conf rule.
+var hbaConnTypes = []string{"local", "host", "hostssl", "hostnossl", "hostgssenc", "hostnogssenc"}
+
+// String returns a human readable name of the format.
+func (f Format) String() string {
+	switch f {
+	case PostgreSQLConf:
+		return "postgresql.conf"
+	case HBAConf:
+		return "pg_hba.conf"
+	}
+	return "unknown"
+}
+
+// DetectFormat inspects a sample of lines from content and guesses whether it is a
+// postgresql.conf or a pg_hba.conf file. Lines with key = value pairs suggest postgresql.conf,
+// while rows with four or more columns that start with a connection type keyword suggest pg_hba.conf.
+// Unknown is returned if content has no settings or the evidence is inconclusive.
+func DetectFormat(content string) Format {
+	var confVotes, hbaVotes, sampled int
+	s := bufio.NewScanner(strings.NewReader(content))
+	for s.Scan() && sampled < detectSampleSize {
+		line := s.Text()
+		if i := strings.IndexRune(line, '#'); i > -1 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		sampled++
+
+		fields := strings.Fields(line)
+		if len(fields) >= 4 && isHBAConnType(fields[0]) {
+			hbaVotes++
+		} else if strings.Contains(line, "=") || len(fields) == 2 {
+			confVotes++
+		}
+	}
+
+	if confVotes > hbaVotes {
+		return PostgreSQLConf
+	} else if hbaVotes > confVotes {
+		return HBAConf
+	}
+	return Unknown
+}
+
+func isHBAConnType(value string) bool {
+	value = strings.ToLower(value)
+	for _, t := range hbaConnTypes {
+		if value == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+package generic
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around changes in unified diffs.
+const diffContext = 3
+
+// diffOp is a single line of an edit script: an unchanged (' '), removed ('-') or added ('+') line.
+type diffOp struct {
+	kind byte
+	text string
+	a, b int // 0-based positions of the line in the old and new text
+}
+
+// DiffToDisk compares the file the configuration was read from with the configuration in memory
+// and returns the differences in unified diff format, or an empty string if there are none.
+// Returns ErrNoBackingFile if the configuration was not read from a file.
+func (c *Conf) DiffToDisk() (string, error) {
+	if c.filename == "" {
+		return "", ErrNoBackingFile
+	}
+	content, err := ioutil.ReadFile(c.filename)
+	if err != nil {
+		return "", fmt.Errorf("could not read file %s: %s", c.filename, err)
+	}
+	return UnifiedDiff(c.filename, c.filename, string(content), c.conf), nil
+}
+
+// UnifiedDiff returns the line differences between the old and new text in unified diff format,
+// or an empty string if the texts are equal.
+func UnifiedDiff(oldName, newName, oldText, newText string) string {
+	a := splitLines(oldText)
+	b := splitLines(newText)
+	ops := diffLines(a, b)
+
+	var out strings.Builder
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		// Extend the hunk while changes are separated by no more than 2*diffContext unchanged lines
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContext {
+				end += diffContext
+				if end > len(ops) {
+					end = len(ops)
+				}
+				break
+			}
+			end = next
+		}
+
+		if out.Len() == 0 {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+		}
+		writeHunk(&out, ops[start:end])
+		i = end
+	}
+	return out.String()
+}
+
+// writeHunk writes a single hunk of a unified diff.
+func writeHunk(out *strings.Builder, ops []diffOp) {
+	var aStart, aLen, bStart, bLen int
+	aStart, bStart = -1, -1
+	for _, op := range ops {
+		if op.kind != '+' {
+			if aStart == -1 {
+				aStart = op.a
+			}
+			aLen++
+		}
+		if op.kind != '-' {
+			if bStart == -1 {
+				bStart = op.b
+			}
+			bLen++
+		}
+	}
+	// Empty ranges are numbered after the line that precedes them
+	if aStart == -1 {
+		aStart = ops[0].a - 1
+	}
+	if bStart == -1 {
+		bStart = ops[0].b - 1
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", aStart+1, aLen, bStart+1, bLen)
+	for _, op := range ops {
+		out.WriteByte(op.kind)
+		out.WriteString(strings.TrimSuffix(op.text, "\n"))
+		out.WriteByte('\n')
+		if !strings.HasSuffix(op.text, "\n") {
+			out.WriteString("\\ No newline at end of file\n")
+		}
+	}
+}
+
+// splitLines splits text into lines, keeping the EOL characters.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes an edit script that turns lines a into lines b, using the longest common subsequence.
+// Lines common to the start and the end of both are matched directly, so that the table of the
+// longest common subsequence only covers the changed part (usually a few lines).
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var ops []diffOp
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{' ', a[i], i, i})
+	}
+	ops = append(ops, diffLCS(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix, prefix)...)
+	for k := suffix; k > 0; k-- {
+		ops = append(ops, diffOp{' ', a[len(a)-k], len(a) - k, len(b) - k})
+	}
+	return ops
+}
+
+// diffLCS computes an edit script that turns lines a into lines b, using the longest common
+// subsequence. The positions in the script are offset by aOffset and bOffset.
+func diffLCS(a, b []string, aOffset, bOffset int) []diffOp {
+	// lcs[i][j] holds the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, diffOp{' ', a[i], aOffset + i, bOffset + j})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', a[i], aOffset + i, bOffset + j})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j], aOffset + i, bOffset + j})
+			j++
+		}
+	}
+	return ops
+}
@@ -7,3 +7,16 @@ var ErrKeyNotFound = fmt.Errorf("key not found")
 
 // ErrEmptyLine is returned if a line contains no key (eg. it is empty or contains only a comment/whitespace).
 var ErrEmptyLine = fmt.Errorf("no key found")
+
+// ErrCommentNotFound is returned if no comment matches the criteria being looked up.
+var ErrCommentNotFound = fmt.Errorf("comment not found")
+
+// ErrLimitExceeded is returned if the configuration being read is larger than the allowed limit.
+var ErrLimitExceeded = fmt.Errorf("configuration size limit exceeded")
+
+// ErrLineLimitExceeded is returned if the configuration being read has more lines than the allowed limit.
+var ErrLineLimitExceeded = fmt.Errorf("configuration line limit exceeded")
+
+// ErrNoBackingFile is returned by methods that need the file a configuration was read from,
+// if the configuration was not read from a file (eg. it was created with New or OpenReader).
+var ErrNoBackingFile = fmt.Errorf("configuration has no backing file")
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
@@ -20,6 +21,8 @@ type Params struct {
 	DefaultQuote           rune   // Default quote to use when updating or adding new string values
 	InlineComment          rune   // Character that denotes inline comments (usually # or ;)
 	AlwaysQuoteStrings     bool   // If true string values are enclosed in quotes even if the values contain no quotes
+	StrictColumns          bool   // If true values that would be split into multiple columns (eg. contain unquoted tabs) are rejected on write
+	GroupDelims            string // Opening and closing character of values that group whitespace separated items (eg. "()"), or empty to disable grouping
 }
 
 // NewParams creates a new configuration with the following defaults:
@@ -30,6 +33,8 @@ type Params struct {
 //  - DefaultQuote:           "
 //  - InlineComment:          #
 //  - AlwaysQuoteStrings:	  false
+//  - StrictColumns:          false
+//  - GroupDelims:            none
 func NewParams() Params {
 	return Params{
 		Whitespace:             " \t\r",
@@ -39,14 +44,18 @@ func NewParams() Params {
 		DefaultQuote:           '"',
 		InlineComment:          '#',
 		AlwaysQuoteStrings:     false,
+		StrictColumns:          false,
+		GroupDelims:            "",
 	}
 }
 
 // Conf can read and write to multi-column whitespace delimited configurations, while preserving existing
 // whitespace, when updating values.
 type Conf struct {
-	conf   string
-	params Params
+	conf     string
+	params   Params
+	filename string   // Backing file, if the configuration was read from a file
+	tags     []string // Tag of every line (see SetLineTags), or nil if lines are not tagged
 }
 
 // New creates a new conf structure for reading/writing to the specified configuration.
@@ -63,8 +72,9 @@ func Open(filename string, params Params) (*Conf, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not read file %s: %s", filename, err)
 	}
-	conf := string(content)
-	return New(conf, params), nil
+	c := New(string(content), params)
+	c.filename = filename
+	return c, nil
 }
 
 // OpenReader reads configuration from a reader.
@@ -77,6 +87,124 @@ func OpenReader(r io.Reader, params Params) (*Conf, error) {
 	return New(conf, params), nil
 }
 
+// OpenLimit opens and reads configuration from a file, failing with ErrLimitExceeded if the file
+// is larger than maxBytes.
+func OpenLimit(filename string, maxBytes int64, params Params) (*Conf, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file %s: %s", filename, err)
+	}
+	defer f.Close()
+	c, err := OpenReaderLimit(f, maxBytes, params)
+	if err != nil {
+		return nil, err
+	}
+	c.filename = filename
+	return c, nil
+}
+
+// OpenReaderLimit reads configuration from a reader, failing with ErrLimitExceeded if the reader
+// returns more than maxBytes. Reading stops as soon as the limit is exceeded.
+func OpenReaderLimit(r io.Reader, maxBytes int64, params Params) (*Conf, error) {
+	content, err := ReadAllLimit(r, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	return New(content, params), nil
+}
+
+// ReadAllLimit reads from r until EOF and returns the data read as a string, failing with
+// ErrLimitExceeded if the reader returns more than maxBytes.
+func ReadAllLimit(r io.Reader, maxBytes int64) (string, error) {
+	content, err := ioutil.ReadAll(io.LimitReader(r, maxBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("could not read configuration from reader: %s", err)
+	}
+	if int64(len(content)) > maxBytes {
+		return "", ErrLimitExceeded
+	}
+	return string(content), nil
+}
+
+// OpenLimitLines opens and reads configuration from a file like OpenLimit, but also fails with
+// ErrLineLimitExceeded if the file has more than maxLines lines.
+func OpenLimitLines(filename string, maxBytes int64, maxLines int, params Params) (*Conf, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file %s: %s", filename, err)
+	}
+	defer f.Close()
+	c, err := OpenReaderLimitLines(f, maxBytes, maxLines, params)
+	if err != nil {
+		return nil, err
+	}
+	c.filename = filename
+	return c, nil
+}
+
+// OpenReaderLimitLines reads configuration from a reader like OpenReaderLimit, but also fails
+// with ErrLineLimitExceeded if the configuration has more than maxLines lines. Lines are counted
+// as by Lines: an EOL character at the end does not start a new line.
+func OpenReaderLimitLines(r io.Reader, maxBytes int64, maxLines int, params Params) (*Conf, error) {
+	content, err := ReadAllLimit(r, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	if countLines(content) > maxLines {
+		return nil, ErrLineLimitExceeded
+	}
+	return New(content, params), nil
+}
+
+// countLines returns the number of lines in the text, counted as by Lines.
+func countLines(text string) int {
+	n := strings.Count(text, "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		n++
+	}
+	return n
+}
+
+// Filename returns the name of the file the configuration was read from, or an empty string
+// if the configuration was not read from a file (eg. it was created with New or OpenReader).
+func (c *Conf) Filename() string {
+	return c.filename
+}
+
+// Save writes the whole configuration back to the file it was read from, preserving the
+// permissions of the file. Returns ErrNoBackingFile if the configuration was not read from a file.
+func (c *Conf) Save() error {
+	if c.filename == "" {
+		return ErrNoBackingFile
+	}
+	info, err := os.Stat(c.filename)
+	if err != nil {
+		return fmt.Errorf("could not stat file %s: %s", c.filename, err)
+	}
+	return c.WriteFile(c.filename, info.Mode().Perm())
+}
+
+// Reload discards any changes and reads the configuration again from the file it was read from.
+// Returns ErrNoBackingFile if the configuration was not read from a file.
+func (c *Conf) Reload() error {
+	if c.filename == "" {
+		return ErrNoBackingFile
+	}
+	content, err := ioutil.ReadFile(c.filename)
+	if err != nil {
+		return fmt.Errorf("could not read file %s: %s", c.filename, err)
+	}
+	c.conf = string(content)
+	c.tags = nil
+	return nil
+}
+
+// Params returns a copy of the parameters that determine the behaviour of generic.Conf.
+// Changing the returned structure does not affect the configuration (use SetParams instead).
+func (c *Conf) Params() Params {
+	return c.params
+}
+
 // SetParams updates the parameters that determine the behaviour of generic.Conf.
 func (c *Conf) SetParams(params Params) {
 	c.params = params
@@ -98,6 +226,16 @@ func (c *Conf) WriteFile(filename string, perm os.FileMode) error {
 	return ioutil.WriteFile(filename, []byte(c.conf), perm)
 }
 
+// WriteFileMkdir writes the whole configuration to a file, creating any missing parent
+// directories (with mode 0755) first. Useful when generating drop-in files for conf.d directories.
+func (c *Conf) WriteFileMkdir(filename string, perm os.FileMode) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("could not create directory %s: %s", dir, err)
+	}
+	return c.WriteFile(filename, perm)
+}
+
 // LookupRow searches for a row that contains the given column value starting at offset,
 // and if found, returns a Row structure with start and end positions of all column values and
 // the offset position of the next line.
@@ -139,11 +277,20 @@ func (c *Conf) parseLine(line string, offset int) (row *Row, err error) {
 	var pos int = -1
 	var insideQuote bool
 	var expectedQuote = c.params.Quotes // Match any of the quote characters specified in params
-	var lastRune rune
+	var escaped bool                    // Whether the previous character was an escaping backslash
+	var depth int                       // Nesting level of groups (see Params.GroupDelims)
+	var groupOpen, groupClose rune = -1, -1
+	if delims := []rune(c.params.GroupDelims); len(delims) == 2 {
+		groupOpen, groupClose = delims[0], delims[1]
+	}
 	var start, end int = -1, -1
 	for i, r := range line {
-		// Stop on inline comment or line ending
-		if r == c.params.InlineComment || r == '\n' {
+		// Stop on line ending or inline comment, unless the comment character is inside a quoted value
+		if r == '\n' {
+			break
+		}
+		if r == c.params.InlineComment && !insideQuote {
+			row.comment = offset + i
 			break
 		}
 
@@ -153,7 +300,7 @@ func (c *Conf) parseLine(line string, offset int) (row *Row, err error) {
 		isQuote := strings.Index(expectedQuote, string(r)) > -1
 
 		if start > -1 {
-			if isQuote && (!c.params.BackslashEscapedQuotes || lastRune != '\\') {
+			if isQuote && !escaped {
 				insideQuote = !insideQuote
 				if insideQuote {
 					expectedQuote = string(r) // Quoted value can be closed only with exactly the same quote character
@@ -161,7 +308,12 @@ func (c *Conf) parseLine(line string, offset int) (row *Row, err error) {
 					expectedQuote = c.params.Quotes // Quote can start with any quote
 				}
 			}
-			if isWhitespace && !insideQuote {
+			if !insideQuote && r == groupOpen {
+				depth++
+			} else if !insideQuote && r == groupClose && depth > 0 {
+				depth--
+			}
+			if isWhitespace && !insideQuote && depth == 0 {
 				// This is the end if a column value, so add this token to the row,
 				end = pos
 				row.addToken(start, end)
@@ -179,10 +331,13 @@ func (c *Conf) parseLine(line string, offset int) (row *Row, err error) {
 			if isQuote {
 				insideQuote = true
 				expectedQuote = string(r) // Quoted value can be closed only with exactly the same quote character
+			} else if r == groupOpen {
+				depth = 1
 			}
 		}
 
-		lastRune = r
+		// A backslash escapes the next character, unless the backslash itself is escaped (eg. \\')
+		escaped = c.params.BackslashEscapedQuotes && r == '\\' && !escaped
 	}
 
 	// Finialize the last column value
@@ -199,6 +354,65 @@ func (c *Conf) parseLine(line string, offset int) (row *Row, err error) {
 	return
 }
 
+// Comment returns the text of the inline comment that follows the column values of the row, without
+// the comment character and surrounding spaces and tabs (eg. "min 128kB" for shared_buffers = 128MB
+// # min 128kB). The second return value is false if the row has no inline comment. A comment
+// character inside a quoted value does not start a comment.
+func (c *Conf) Comment(row *Row) (string, bool) {
+	if row == nil || row.comment < 0 || row.comment >= len(c.conf) {
+		return "", false
+	}
+	text := c.conf[row.comment+len(string(c.params.InlineComment)):]
+	if end := strings.IndexByte(text, '\n'); end != -1 {
+		text = text[:end]
+	}
+	return strings.Trim(strings.TrimSuffix(text, "\r"), " \t"), true
+}
+
+// SetComment replaces the inline comment of the row, keeping the column values and the whitespace
+// between them and the comment. If the row has no inline comment, one is added after the last
+// column, separated by a single space. An empty comment removes the inline comment, along with
+// the whitespace preceding it.
+func (c *Conf) SetComment(row *Row, comment string) error {
+	if row == nil {
+		return errors.New("could not set comment for a nil row")
+	}
+	if strings.ContainsAny(comment, "\r\n") {
+		return fmt.Errorf("comment %q spans multiple lines", comment)
+	}
+	last, err := row.Token(row.ColCount() - 1)
+	if err != nil {
+		return fmt.Errorf("could not retrieve token for last column: %s", err)
+	}
+	if last.End < 0 || last.End > len(c.conf) {
+		return fmt.Errorf("invalid token for column %d", row.ColCount()-1)
+	}
+
+	marker := string(c.params.InlineComment)
+	text := ""
+	if comment != "" {
+		text = marker + " " + comment
+	}
+	if row.comment < last.End || row.comment >= len(c.conf) {
+		if text != "" {
+			c.replace(last.End, last.End, " "+text)
+		}
+		return nil
+	}
+
+	end := len(c.conf)
+	if i := strings.IndexByte(c.conf[row.comment:], '\n'); i != -1 {
+		end = row.comment + i
+	}
+	end = row.comment + len(strings.TrimRight(c.conf[row.comment:end], "\r"))
+	start := row.comment
+	if text == "" {
+		start = last.End
+	}
+	c.replace(start, end, text)
+	return nil
+}
+
 // HasQuotesOrWhitespace tests if the value contains any of the quote characters specified in Params.DefaultQuote
 // or a whitespace character specified in Params.Whitespace.
 func (c *Conf) HasQuotesOrWhitespace(value string) bool {
@@ -215,8 +429,12 @@ func (c *Conf) HasQuotesOrWhitespace(value string) bool {
 }
 
 // EscapeQuotes escapes quote characters by double-quoting them.
-// Recognizes and double-quotes characters specified in Params.Quotes.
+// If Params.BackslashEscapedQuotes is true, backslashes are escaped too (by doubling them),
+// so that they are not mistaken for escape characters when the value is read back.
 func (c *Conf) EscapeQuotes(value string, quote rune) string {
+	if c.params.BackslashEscapedQuotes {
+		value = strings.Replace(value, `\`, `\\`, -1)
+	}
 	single := string(quote)
 	doubled := strings.Repeat(single, 2)
 	value = strings.Replace(value, single, doubled, -1)
@@ -224,17 +442,26 @@ func (c *Conf) EscapeQuotes(value string, quote rune) string {
 }
 
 // UnescapeQuotes unescapes double quoted or backslash quoted values,
-// depending on settings in Params.
+// depending on settings in Params. If Params.BackslashEscapedQuotes is true,
+// escaped backslashes (\\) are unescaped too.
 func (c *Conf) UnescapeQuotes(value string, quote rune) string {
-	single := string(quote)
-	doubled := strings.Repeat(single, 2)
-	value = strings.Replace(value, doubled, single, -1)
-
-	if c.params.BackslashEscapedQuotes {
-		escaped := `\` + single
-		value = strings.Replace(value, escaped, single, -1)
+	var b strings.Builder
+	runes := []rune(value)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if i+1 < len(runes) {
+			next := runes[i+1]
+			isDoubled := r == quote && next == quote
+			isEscaped := c.params.BackslashEscapedQuotes && r == '\\' && (next == quote || next == '\\')
+			if isDoubled || isEscaped {
+				b.WriteRune(next)
+				i++
+				continue
+			}
+		}
+		b.WriteRune(r)
 	}
-	return value
+	return b.String()
 }
 
 // Quote escapes any quotes in value by double-quoting them and then encloses the escaped value
@@ -244,6 +471,43 @@ func (c *Conf) Quote(value string) string {
 	return string(quote) + c.EscapeQuotes(value, quote) + string(quote)
 }
 
+// NormalizeQuotes rewrites every quoted column value that is enclosed in a quote other than
+// Params.DefaultQuote to use Params.DefaultQuote, re-escaping any quotes inside the value.
+// Unquoted values are left unchanged. Returns the number of values changed.
+func (c *Conf) NormalizeQuotes() (int, error) {
+	type change struct {
+		row   *Row
+		col   int
+		value string
+	}
+	var changes []change
+	defaultQuote := string(c.params.DefaultQuote)
+	for _, line := range c.Lines() {
+		row, err := c.RowOf(line)
+		if err != nil {
+			continue
+		}
+		for col := 0; col < row.ColCount(); col++ {
+			raw, err := c.Raw(row, col)
+			if err != nil {
+				return 0, err
+			}
+			if len(raw) < 2 || raw[:1] == defaultQuote || c.Dequote(raw) == raw {
+				continue
+			}
+			changes = append(changes, change{row, col, c.Quote(c.Dequote(raw))})
+		}
+	}
+
+	// Apply changes in reverse order, so that positions of preceding values remain valid
+	for i := len(changes) - 1; i >= 0; i-- {
+		if err := c.SetRaw(changes[i].row, changes[i].col, changes[i].value); err != nil {
+			return 0, err
+		}
+	}
+	return len(changes), nil
+}
+
 // Dequote removes enclosing quotes and unescapes double quotes and backslash escaped quotes in values.
 func (c *Conf) Dequote(value string) string {
 	if len(value) < 2 {
@@ -292,6 +556,66 @@ func (c *Conf) Raw(row *Row, col int) (string, error) {
 	return raw, nil
 }
 
+// ColumnRaw retrieves the raw value of the nth column at the specified row, counting all columns
+// on the row, regardless of the whitespace separating them (eg. the options of a pg_hba.conf rule,
+// that follow the method column). It is an alias of Raw.
+func (c *Conf) ColumnRaw(row *Row, col int) (string, error) {
+	return c.Raw(row, col)
+}
+
+// RawBytes retrieves the raw value of the column at the specified row like Raw, but as a slice
+// of bytes, for callers working with byte slices. The returned slice is a copy and can be modified.
+func (c *Conf) RawBytes(row *Row, col int) ([]byte, error) {
+	raw, err := c.Raw(row, col)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(raw), nil
+}
+
+// RawRest retrieves the raw text from the value of the column at the specified row through the
+// value of the last column on the row, including any whitespace between the columns, but
+// excluding whitespace after the last column and any inline comment.
+func (c *Conf) RawRest(row *Row, col int) (string, error) {
+	if row == nil {
+		return "", errors.New("could not retrieve raw value for a nil row")
+	}
+	first, err := row.Token(col)
+	if err != nil {
+		return "", fmt.Errorf("could not retrieve token for column %d: %s", col, err)
+	}
+	last, err := row.Token(row.ColCount() - 1)
+	if err != nil {
+		return "", fmt.Errorf("could not retrieve token for last column: %s", err)
+	}
+	if first.Start < 0 || last.End < first.Start {
+		return "", fmt.Errorf("invalid tokens for columns %d to %d", col, row.ColCount()-1)
+	}
+	return c.conf[first.Start:last.End], nil
+}
+
+// SetRawRest replaces the raw text from the value of the column at the specified row through the
+// value of the last column on the row (see RawRest), preserving whitespace after the last column
+// and any inline comment. The new value can contain several columns.
+func (c *Conf) SetRawRest(row *Row, col int, value string) error {
+	if row == nil {
+		return errors.New("could not set raw value for a nil row")
+	}
+	first, err := row.Token(col)
+	if err != nil {
+		return fmt.Errorf("could not retrieve token for column %d: %s", col, err)
+	}
+	last, err := row.Token(row.ColCount() - 1)
+	if err != nil {
+		return fmt.Errorf("could not retrieve token for last column: %s", err)
+	}
+	if first.Start < 0 || last.End < first.Start || last.End > len(c.conf) {
+		return fmt.Errorf("invalid tokens for columns %d to %d", col, row.ColCount()-1)
+	}
+	c.replace(first.Start, last.End, value)
+	return nil
+}
+
 // String retrieves the value of the column at an existing row as a dequoted string.
 // Removes the enclosing quotes and unescapes double quotes and backslashed quotes in value.
 func (c *Conf) String(row *Row, col int) (string, error) {
@@ -346,14 +670,24 @@ func (c *Conf) EnsureEndsWithEOL() {
 		return
 	}
 
-	c.conf += "\n"
+	c.replace(len(c.conf), len(c.conf), "\n")
 
 	return
 }
 
+// clearIfBlank empties a configuration that contains nothing but whitespace and blank lines,
+// so that the first line appended to it starts at the beginning of the file.
+func (c *Conf) clearIfBlank() {
+	if strings.Trim(c.conf, c.params.Whitespace+"\n") == "" {
+		c.replace(0, len(c.conf), "")
+	}
+}
+
 // Append adds a new row with the given column values and returns a Row structure describing the
-// line appended.
+// line appended. If the configuration contains only whitespace and blank lines, they are removed
+// first, so that the new row becomes the first line.
 func (c *Conf) Append(values ...string) (*Row, error) {
+	c.clearIfBlank()
 	c.EnsureEndsWithEOL()
 
 	line := ""
@@ -369,10 +703,66 @@ func (c *Conf) Append(values ...string) (*Row, error) {
 	if err != nil {
 		return nil, errors.New("FAILED to parse the line that was about to be appended")
 	}
-	c.conf += line
+	if c.params.StrictColumns && row.ColCount() != len(values) {
+		return nil, fmt.Errorf("line to append has %d columns, want %d", row.ColCount(), len(values))
+	}
+	c.replace(len(c.conf), len(c.conf), line)
 	return row, nil
 }
 
+// InsertAfter adds a new row with the given column values on a new line directly below the line
+// that holds the given row, and returns a Row structure describing the line inserted. The new line
+// is indented with the same whitespace as the line holding the given row.
+func (c *Conf) InsertAfter(row *Row, values ...string) (*Row, error) {
+	if row == nil {
+		return nil, errors.New("could not insert after a nil row")
+	}
+	token, err := row.Token(0)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve token for first column: %s", err)
+	}
+	lineStart := strings.LastIndexByte(c.conf[:token.Start], '\n') + 1
+	indent := c.conf[lineStart:token.Start]
+
+	insertPos := strings.IndexByte(c.conf[token.Start:], '\n')
+	if insertPos == -1 {
+		c.EnsureEndsWithEOL()
+		insertPos = len(c.conf)
+	} else {
+		insertPos += token.Start + 1
+	}
+
+	line := indent + strings.Join(values, c.params.DefaultDelim)
+	newRow, err := c.parseLine(line, insertPos)
+	if err != nil {
+		return nil, errors.New("FAILED to parse the line that was about to be inserted")
+	}
+	eol := "\n"
+	if insertPos == len(c.conf) {
+		eol = ""
+	}
+	c.replace(insertPos, insertPos, line+eol)
+	return newRow, nil
+}
+
+// AppendComment adds a new line that contains only a comment with the given text.
+// Like Append, it removes whitespace and blank lines from a configuration that contains nothing else.
+func (c *Conf) AppendComment(text string) {
+	c.clearIfBlank()
+	c.EnsureEndsWithEOL()
+	c.replace(len(c.conf), len(c.conf), string(c.params.InlineComment)+" "+text)
+}
+
+// AppendRawLine adds the given line to the end of the configuration exactly as provided, without
+// parsing, quoting or reformatting it. Like Append, it removes whitespace and blank lines from
+// a configuration that contains nothing else. This is the lowest-level way of writing: callers are
+// responsible for the line being valid (eg. containing no EOL characters and properly quoted values).
+func (c *Conf) AppendRawLine(line string) {
+	c.clearIfBlank()
+	c.EnsureEndsWithEOL()
+	c.replace(len(c.conf), len(c.conf), line)
+}
+
 // SetRaw replaces the raw value of the column at an existing row, including any quotes,
 // while preserving whitespace on the line.
 func (c *Conf) SetRaw(row *Row, col int, value string) error {
@@ -392,23 +782,119 @@ func (c *Conf) SetRaw(row *Row, col int, value string) error {
 	if oldSize <= 0 {
 		return fmt.Errorf("got value size of %d, want size > 0", oldSize)
 	}
+	if c.params.StrictColumns {
+		if valueRow, err := c.parseLine(value, 0); err != nil || valueRow.ColCount() != 1 {
+			return fmt.Errorf("value %q would not be parsed as a single column", value)
+		}
+	}
 
-	c.conf = c.conf[:offset] + value + c.conf[offset+oldSize:]
+	c.replace(offset, offset+oldSize, value)
 
 	return nil
 }
 
+// NormalizeDelim replaces the whitespace between the value of column col and the value of the next
+// column with Params.DefaultDelim. Returns true if the delimiter was changed.
+func (c *Conf) NormalizeDelim(row *Row, col int) (bool, error) {
+	if row == nil {
+		return false, errors.New("could not normalize delimiter for a nil row")
+	}
+	left, err := row.Token(col)
+	if err != nil {
+		return false, fmt.Errorf("could not retrieve token for column: %s", err)
+	}
+	right, err := row.Token(col + 1)
+	if err != nil {
+		return false, fmt.Errorf("could not retrieve token for next column: %s", err)
+	}
+	if c.conf[left.End:right.Start] == c.params.DefaultDelim {
+		return false, nil
+	}
+	c.replace(left.End, right.Start, c.params.DefaultDelim)
+	return true, nil
+}
+
+// SetRawAligned replaces the raw value of the column at an existing row, like SetRaw, and then
+// adjusts the whitespace after it, so that the next column keeps its original starting position
+// where possible. At least one whitespace character is kept between the columns. If the whitespace
+// contains tabs, the column is re-aligned with tabs (assuming tab stops every 8 characters).
+func (c *Conf) SetRawAligned(row *Row, col int, value string) error {
+	if row == nil || !row.HasColumn(col+1) {
+		// Last column, so there is nothing to align
+		return c.SetRaw(row, col, value)
+	}
+	token, err := row.Token(col)
+	if err != nil {
+		return fmt.Errorf("could not retrieve token for column: %s", err)
+	}
+	next, err := row.Token(col + 1)
+	if err != nil {
+		return fmt.Errorf("could not retrieve token for next column: %s", err)
+	}
+
+	lineStart := strings.LastIndexByte(c.conf[:token.Start], '\n') + 1
+	gap := c.conf[token.End:next.Start]
+	target := visualWidth(c.conf[lineStart:next.Start])
+	end := visualWidth(c.conf[lineStart:token.Start] + value)
+
+	var newGap string
+	if strings.ContainsRune(gap, '\t') {
+		newGap = "\t"
+		for pos := (end/tabWidth + 1) * tabWidth; pos < target; pos += tabWidth {
+			newGap += "\t"
+		}
+	} else if target-end > 1 {
+		newGap = strings.Repeat(" ", target-end)
+	} else {
+		newGap = " "
+	}
+
+	oldSize := token.End - token.Start
+	if err := c.SetRaw(row, col, value); err != nil {
+		return err
+	}
+	gapStart := token.Start + len(value)
+	gapEnd := next.Start + len(value) - oldSize
+	c.replace(gapStart, gapEnd, newGap)
+	return nil
+}
+
+// tabWidth is the distance between tab stops, used when aligning columns.
+const tabWidth = 8
+
+// visualWidth returns the number of character cells the text occupies, with tabs expanded
+// to the next tab stop.
+func visualWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		if r == '\t' {
+			width = (width/tabWidth + 1) * tabWidth
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// quoteIfNeeded encloses the value in quotes if Params.AlwaysQuoteStrings is set, or
+// if the value contains any quotes or whitespace.
+func (c *Conf) quoteIfNeeded(value string) string {
+	if c.params.AlwaysQuoteStrings || c.HasQuotesOrWhitespace(value) {
+		return c.Quote(value)
+	}
+	return value
+}
+
 // SetString encloses the given value with single quotes and updates the existing value
 // at the specified row and column, while preserving whitespace on line.
 func (c *Conf) SetString(row *Row, col int, value string) error {
-	var raw string
-	if c.params.AlwaysQuoteStrings || c.HasQuotesOrWhitespace(value) {
-		raw = c.Quote(value)
-	} else {
-		raw = value
-	}
+	return c.SetRaw(row, col, c.quoteIfNeeded(value))
+}
 
-	return c.SetRaw(row, col, raw)
+// SetStringAligned updates the existing value at the specified row and column like SetString,
+// and then re-aligns the next column like SetRawAligned.
+func (c *Conf) SetStringAligned(row *Row, col int, value string) error {
+	return c.SetRawAligned(row, col, c.quoteIfNeeded(value))
 }
 
 // SetInt updates the existing value at the specified row and column with an unquoted integer,
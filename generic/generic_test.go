@@ -0,0 +1,441 @@
+package generic_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/quasoft/pgconf/generic"
+)
+
+func TestWriteFileMkdir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgconf")
+	if err != nil {
+		t.Fatalf("TempDir() failed: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	want := "port = 5432\n"
+	conf := generic.New(want, generic.NewParams())
+	filename := filepath.Join(dir, "conf.d", "nested", "99-tuning.conf")
+	err = conf.WriteFileMkdir(filename, 0644)
+	if err != nil {
+		t.Fatalf("WriteFileMkdir(%q) errored with '%s', wanted no error", filename, err)
+	}
+
+	got, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile(%q) failed: %s", filename, err)
+	}
+	if string(got) != want {
+		t.Errorf("WriteFileMkdir(%q) wrote %q, want %q", filename, got, want)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	readFile := func(filename string) string {
+		content, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("ReadFile(%q) failed: %s", filename, err)
+		}
+		return string(content)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		want    generic.Format
+	}{
+		{"Sample postgresql.conf", readFile(filepath.Join("..", "conf", "testdata", "postgresql.conf")), generic.PostgreSQLConf},
+		{"Sample pg_hba.conf", readFile(filepath.Join("..", "hba", "testdata", "sample.conf")), generic.HBAConf},
+		{"Empty", "", generic.Unknown},
+		{"Comments only", "# just a comment\n\n# another one\n", generic.Unknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := generic.DetectFormat(tt.content)
+			if got != tt.want {
+				t.Errorf("DetectFormat() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommentMatching(t *testing.T) {
+	content := "# Managed by provisioning\n" +
+		"# last updated: 2018-03-04 10:20:30\n" +
+		"port = 5432 # last updated: inline comments are ignored\n" +
+		"\t# last updated: 2018-03-05 11:00:00\n"
+	conf := generic.New(content, generic.NewParams())
+
+	re := regexp.MustCompile(`^last updated: (\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})$`)
+	comments, line, err := conf.CommentMatching(re)
+	if err != nil {
+		t.Fatalf("CommentMatching() errored with '%s', wanted no error", err)
+	}
+	want := []string{"last updated: 2018-03-04 10:20:30", "last updated: 2018-03-05 11:00:00"}
+	if !reflect.DeepEqual(comments, want) {
+		t.Errorf("CommentMatching() = %q, want %q", comments, want)
+	}
+	if line != 2 {
+		t.Errorf("CommentMatching() line = %d, want %d", line, 2)
+	}
+	if got := re.FindStringSubmatch(comments[0])[1]; got != "2018-03-04 10:20:30" {
+		t.Errorf("CommentMatching() timestamp = %q, want %q", got, "2018-03-04 10:20:30")
+	}
+
+	_, _, err = conf.CommentMatching(regexp.MustCompile(`^no such comment`))
+	if err != generic.ErrCommentNotFound {
+		t.Errorf("CommentMatching() errored with '%v', want '%s'", err, generic.ErrCommentNotFound)
+	}
+}
+
+func TestParams(t *testing.T) {
+	want := generic.NewParams()
+	conf := generic.New("port 5432\n", want)
+
+	got := conf.Params()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Params() = %+v, want %+v", got, want)
+	}
+
+	got.DefaultDelim = " = "
+	got.InlineComment = ';'
+	if again := conf.Params(); !reflect.DeepEqual(again, want) {
+		t.Errorf("Params() = %+v after changing the returned copy, want %+v", again, want)
+	}
+
+	params := generic.NewParams()
+	params.DefaultQuote = '\''
+	conf.SetParams(params)
+	if got := conf.Params(); got.DefaultQuote != '\'' {
+		t.Errorf("Params().DefaultQuote = %q after SetParams, want %q", got.DefaultQuote, '\'')
+	}
+}
+
+func TestNormalizeQuotes(t *testing.T) {
+	params := generic.NewParams()
+	params.Whitespace += "="
+	params.DefaultQuote = '\''
+	conf := generic.New("a = 'single'\n"+
+		"b = \"double\"  # comment\n"+
+		"c = \"it's\"\n"+
+		"d = \"say \\\"hi\\\"\"\n"+
+		"e = unquoted\n"+
+		"f = \"\"\n", params)
+
+	n, err := conf.NormalizeQuotes()
+	if err != nil {
+		t.Fatalf("NormalizeQuotes() errored with '%s', wanted no error", err)
+	}
+	if n != 4 {
+		t.Errorf("NormalizeQuotes() = %d, want %d", n, 4)
+	}
+	want := "a = 'single'\n" +
+		"b = 'double'  # comment\n" +
+		"c = 'it''s'\n" +
+		"d = 'say \"hi\"'\n" +
+		"e = unquoted\n" +
+		"f = ''\n"
+	if got := conf.All(); got != want {
+		t.Errorf("NormalizeQuotes() changed configuration to %q, want %q", got, want)
+	}
+}
+
+func TestFileProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    generic.Profile
+	}{
+		{"LF", "port = 5432\nssl = on\n", generic.Profile{BOM: false, LineEnding: generic.LF, EndsWithEOL: true}},
+		{"CRLF", "port = 5432\r\nssl = on\r\n", generic.Profile{BOM: false, LineEnding: generic.CRLF, EndsWithEOL: true}},
+		{"CR", "port = 5432\rssl = on", generic.Profile{BOM: false, LineEnding: generic.CR, EndsWithEOL: false}},
+		{"Mixed, mostly CRLF", "a = 1\r\nb = 2\r\nc = 3\n", generic.Profile{BOM: false, LineEnding: generic.CRLF, EndsWithEOL: true}},
+		{"BOM", "\ufeffport = 5432\n", generic.Profile{BOM: true, LineEnding: generic.LF, EndsWithEOL: true}},
+		{"Single line", "port = 5432", generic.Profile{BOM: false, LineEnding: generic.NoLineEnding, EndsWithEOL: false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := generic.New(tt.content, generic.NewParams()).FileProfile()
+			if got != tt.want {
+				t.Errorf("FileProfile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    string
+	}{
+		{"Equal", "a\nb\n", "a\nb\n", ""},
+		{"Added line at end", "a\n", "a\nb\n", "--- old\n+++ new\n@@ -1,1 +1,2 @@\n a\n+b\n"},
+		{"Removed only line", "a\n", "", "--- old\n+++ new\n@@ -1,1 +0,0 @@\n-a\n"},
+		{"No EOL", "a\nb", "a\nc", "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n"},
+		{
+			"Two hunks",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			"--- old\n+++ new\n@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n@@ -9,4 +10,3 @@\n 9\n 10\n 11\n-12\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := generic.UnifiedDiff("old", "new", tt.oldText, tt.newText)
+			if got != tt.want {
+				t.Errorf("UnifiedDiff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff_LargeFile(t *testing.T) {
+	var lines []string
+	for i := 1; i <= 10000; i++ {
+		lines = append(lines, strconv.Itoa(i))
+	}
+	oldText := strings.Join(lines, "\n") + "\n"
+	newText := strings.Replace(oldText, "\n5000\n", "\nfive thousand\n", 1)
+	want := "--- old\n+++ new\n@@ -4997,7 +4997,7 @@\n 4997\n 4998\n 4999\n-5000\n+five thousand\n 5001\n 5002\n 5003\n"
+	if got := generic.UnifiedDiff("old", "new", oldText, newText); got != want {
+		t.Errorf("UnifiedDiff() = %q, want %q", got, want)
+	}
+}
+
+func TestRemoveLine(t *testing.T) {
+	c := generic.New("a 1\nb 2\nc 3", generic.NewParams())
+	lines := c.Lines()
+	if err := c.RemoveLine(lines[2]); err != nil {
+		t.Fatalf("RemoveLine() errored with '%s', wanted no error", err)
+	}
+	if err := c.RemoveLine(lines[0]); err != nil {
+		t.Fatalf("RemoveLine() errored with '%s', wanted no error", err)
+	}
+	if got, want := c.All(), "b 2\n"; got != want {
+		t.Errorf("RemoveLine() changed configuration to %q, want %q", got, want)
+	}
+	if err := c.RemoveLine(lines[1]); err == nil {
+		t.Errorf("RemoveLine() with stale line did not error, wanted error")
+	}
+}
+
+func TestInsertRawLines(t *testing.T) {
+	c := generic.New("a 1\nb 2", generic.NewParams())
+	lines := c.Lines()
+	if err := c.InsertRawLines(lines[1], "# before b", "x\t\t9"); err != nil {
+		t.Fatalf("InsertRawLines() errored with '%s', wanted no error", err)
+	}
+	if got, want := c.All(), "a 1\n# before b\nx\t\t9\nb 2"; got != want {
+		t.Errorf("InsertRawLines() changed configuration to %q, want %q", got, want)
+	}
+	if err := c.InsertRawLines(generic.Line{Number: 9, Start: 100}, "c 3"); err == nil {
+		t.Errorf("InsertRawLines() with invalid line did not error, wanted error")
+	}
+}
+
+func TestCommentOutLine(t *testing.T) {
+	c := generic.New("a 1\n  b 2  # two\n", generic.NewParams())
+	if err := c.CommentOutLine(c.Lines()[1]); err != nil {
+		t.Fatalf("CommentOutLine() errored with '%s', wanted no error", err)
+	}
+	if got, want := c.All(), "a 1\n  #b 2  # two\n"; got != want {
+		t.Errorf("CommentOutLine() changed configuration to %q, want %q", got, want)
+	}
+	if err := c.UncommentLine(c.Lines()[1]); err != nil {
+		t.Fatalf("UncommentLine() errored with '%s', wanted no error", err)
+	}
+	if got, want := c.All(), "a 1\n  b 2  # two\n"; got != want {
+		t.Errorf("UncommentLine() changed configuration to %q, want %q", got, want)
+	}
+}
+
+func TestComment(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   string
+		wantOk bool
+	}{
+		{"comment", "shared_buffers 128MB   # min 128kB\n", "min 128kB", true},
+		{"hash inside quotes", "search_path '\"#user\"'  # schemas\n", "schemas", true},
+		{"quoted hash only", "search_path '\"#user\"'\n", "", false},
+		{"empty comment", "port 5432 #\r\n", "", true},
+		{"no comment", "port 5432\n", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := generic.New(tt.line, generic.NewParams())
+			row, err := c.RowOf(c.Lines()[0])
+			if err != nil {
+				t.Fatalf("RowOf() errored with '%s', wanted no error", err)
+			}
+			got, ok := c.Comment(row)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("Comment() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestComment_KeepsDelimiters(t *testing.T) {
+	params := generic.NewParams()
+	params.Whitespace += "="
+	c := generic.New("port = 5432 # = default =\n", params)
+	row, err := c.RowOf(c.Lines()[0])
+	if err != nil {
+		t.Fatalf("RowOf() errored with '%s', wanted no error", err)
+	}
+	if got, ok := c.Comment(row); got != "= default =" || !ok {
+		t.Errorf("Comment() = %q, %v, want %q, %v", got, ok, "= default =", true)
+	}
+}
+
+func TestSetRawRest(t *testing.T) {
+	c := generic.New("host all  all 10.0.0.0/8  cert map=a   # comment\n", generic.NewParams())
+	row, err := c.RowOf(c.Lines()[0])
+	if err != nil {
+		t.Fatalf("RowOf() errored with '%s', wanted no error", err)
+	}
+	if err := c.SetRawRest(row, 5, "map=b clientcert=1"); err != nil {
+		t.Fatalf("SetRawRest() errored with '%s', wanted no error", err)
+	}
+	want := "host all  all 10.0.0.0/8  cert map=b clientcert=1   # comment\n"
+	if got := c.All(); got != want {
+		t.Errorf("SetRawRest() changed configuration to %q, want %q", got, want)
+	}
+}
+
+func TestAppendRawLine(t *testing.T) {
+	c := generic.New("a 1", generic.NewParams())
+	c.AppendRawLine("b\t=\t'x  y'   # aligned by hand")
+	want := "a 1\nb\t=\t'x  y'   # aligned by hand"
+	if got := c.All(); got != want {
+		t.Errorf("AppendRawLine() changed configuration to %q, want %q", got, want)
+	}
+}
+
+func TestJoinColumns(t *testing.T) {
+	c := generic.New("key  a [b]  c # comment", generic.NewParams())
+	row, err := c.RowOf(c.Lines()[0])
+	if err != nil {
+		t.Fatalf("RowOf() errored with '%s', wanted no error", err)
+	}
+	joined := row.JoinColumns(1)
+	if joined.ColCount() != 2 {
+		t.Fatalf("JoinColumns(1).ColCount() = %d, want %d", joined.ColCount(), 2)
+	}
+	if got, _ := c.Raw(joined, 1); got != "a [b]  c" {
+		t.Errorf("Raw() of joined column = %q, want %q", got, "a [b]  c")
+	}
+	if row.ColCount() != 4 {
+		t.Errorf("JoinColumns() modified the original row to %d columns, want %d", row.ColCount(), 4)
+	}
+}
+
+func TestGroupDelims(t *testing.T) {
+	params := generic.NewParams()
+	params.GroupDelims = "()"
+	c := generic.New("host (a b) ('c )' d)  (e (f g)) h # (comment)", params)
+	row, err := c.RowOf(c.Lines()[0])
+	if err != nil {
+		t.Fatalf("RowOf() errored with '%s', wanted no error", err)
+	}
+	want := []string{"host", "(a b)", "('c )' d)", "(e (f g))", "h"}
+	if row.ColCount() != len(want) {
+		t.Fatalf("ColCount() = %d, want %d", row.ColCount(), len(want))
+	}
+	for col, w := range want {
+		if got, _ := c.Raw(row, col); got != w {
+			t.Errorf("Raw(row, %d) = %q, want %q", col, got, w)
+		}
+	}
+}
+
+func TestSetString_RoundTripQuotes(t *testing.T) {
+	values := []string{
+		`it's a "quote"`,
+		`'"`,
+		`""''`,
+		`back\slash`,
+		`c:\`,
+		`a\'b\"c`,
+	}
+	for _, value := range values {
+		t.Run(value, func(t *testing.T) {
+			c := generic.New("key value\n", generic.NewParams())
+			row, err := c.RowOf(c.Lines()[0])
+			if err != nil {
+				t.Fatalf("RowOf() errored with '%s', wanted no error", err)
+			}
+			if err := c.SetString(row, 1, value); err != nil {
+				t.Fatalf("SetString(%q) errored with '%s', wanted no error", value, err)
+			}
+			row, err = c.RowOf(c.Lines()[0])
+			if err != nil {
+				t.Fatalf("RowOf() errored with '%s', wanted no error", err)
+			}
+			got, err := c.String(row, 1)
+			if err != nil {
+				t.Fatalf("String() errored with '%s', wanted no error", err)
+			}
+			if got != value {
+				t.Errorf("String() = %q after SetString(%q) wrote %q, want %q", got, value, c.All(), value)
+			}
+		})
+	}
+}
+
+func TestLineTags(t *testing.T) {
+	c := generic.New("a 1\nb 2\nc 3\n", generic.NewParams())
+	if tags := c.LineTags(); tags != nil {
+		t.Errorf("LineTags() = %q before SetLineTags, want nil", tags)
+	}
+	if err := c.SetLineTags([]string{"x", "y"}); err == nil {
+		t.Errorf("SetLineTags() with too few tags did not error, wanted error")
+	}
+	if err := c.SetLineTags([]string{"x", "y", "z"}); err != nil {
+		t.Fatalf("SetLineTags() errored with '%s', wanted no error", err)
+	}
+
+	if err := c.InsertRawLines(c.Lines()[1], "# before b"); err != nil {
+		t.Fatalf("InsertRawLines() errored with '%s', wanted no error", err)
+	}
+	if err := c.RemoveLine(c.Lines()[0]); err != nil {
+		t.Fatalf("RemoveLine() errored with '%s', wanted no error", err)
+	}
+	row, err := c.RowOf(c.Lines()[2])
+	if err != nil {
+		t.Fatalf("RowOf() errored with '%s', wanted no error", err)
+	}
+	if err := c.SetRaw(row, 1, "33"); err != nil {
+		t.Fatalf("SetRaw() errored with '%s', wanted no error", err)
+	}
+	if _, err := c.Append("d", "4"); err != nil {
+		t.Fatalf("Append() errored with '%s', wanted no error", err)
+	}
+
+	got := c.LineTags()
+	want := []string{"", "y", "z", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LineTags() = %q after changing %q, want %q", got, c.All(), want)
+	}
+}
+
+func TestCollapseBlankLines(t *testing.T) {
+	c := generic.New("a 1\n\n \n\t\nb 2\n\nc 3\n\n\n", generic.NewParams())
+	if got := c.CollapseBlankLines(1); got != 3 {
+		t.Errorf("CollapseBlankLines(1) = %d, want %d", got, 3)
+	}
+	if got, want := c.All(), "a 1\n\nb 2\n\nc 3\n\n"; got != want {
+		t.Errorf("CollapseBlankLines(1) changed configuration to %q, want %q", got, want)
+	}
+}
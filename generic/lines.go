@@ -0,0 +1,227 @@
+package generic
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Line describes a single physical line of the configuration.
+type Line struct {
+	Number int    // 1-based line number
+	Start  int    // Position of the first character of the line
+	End    int    // Position after the last character of the line, including the EOL character (if any)
+	Text   string // Content of the line, without the EOL character
+}
+
+// Lines splits the configuration into physical lines. An EOL character at the end of the
+// configuration does not start a new line.
+func (c *Conf) Lines() []Line {
+	var lines []Line
+	offset := 0
+	for number := 1; offset < len(c.conf); number++ {
+		end := strings.IndexByte(c.conf[offset:], '\n')
+		if end == -1 {
+			end = len(c.conf)
+		} else {
+			end += offset + 1
+		}
+		lines = append(lines, Line{
+			Number: number,
+			Start:  offset,
+			End:    end,
+			Text:   strings.TrimSuffix(c.conf[offset:end], "\n"),
+		})
+		offset = end
+	}
+	return lines
+}
+
+// SetLineTags attaches a tag (eg. the name of the file the line was read from) to every line of
+// the configuration, or removes all tags if tags is nil. Tags follow their lines as the
+// configuration is changed: changed lines keep their tag, while inserted lines get an empty tag.
+// Returns an error if the number of tags differs from the number of lines.
+func (c *Conf) SetLineTags(tags []string) error {
+	if tags == nil {
+		c.tags = nil
+		return nil
+	}
+	if lines := len(c.Lines()); len(tags) != lines {
+		return fmt.Errorf("got %d tags, want one for each of %d lines", len(tags), lines)
+	}
+	// Tags are kept for every part of the configuration separated by EOL characters, including
+	// the empty part after an EOL character at the end
+	c.tags = make([]string, strings.Count(c.conf, "\n")+1)
+	copy(c.tags, tags)
+	return nil
+}
+
+// LineTags returns the tag of every line of the configuration (see SetLineTags), or nil if lines
+// are not tagged.
+func (c *Conf) LineTags() []string {
+	if c.tags == nil {
+		return nil
+	}
+	tags := make([]string, len(c.Lines()))
+	copy(tags, c.tags)
+	return tags
+}
+
+// replace replaces the text between the start and end positions with the given text, while
+// keeping tags of lines in sync (see SetLineTags).
+func (c *Conf) replace(start, end int, text string) {
+	if c.tags != nil {
+		first := strings.Count(c.conf[:start], "\n")
+		last := first + strings.Count(c.conf[start:end], "\n")
+		tags := make([]string, strings.Count(text, "\n")+1) // Inserted lines get an empty tag
+		if end < len(c.conf) {
+			tags[len(tags)-1] = c.tags[last] // The rest of the last line remains
+		}
+		if start > 0 && c.conf[start-1] != '\n' {
+			tags[0] = c.tags[first] // The beginning of the first line remains
+		}
+		// Build a new slice, as tags can be shared with copies of the configuration
+		tags = append(append(append([]string{}, c.tags[:first]...), tags...), c.tags[last+1:]...)
+		c.tags = tags
+	}
+	c.conf = c.conf[:start] + text + c.conf[end:]
+}
+
+// LineAt returns the line with the given 1-based line number.
+func (c *Conf) LineAt(number int) (Line, error) {
+	lines := c.Lines()
+	if number < 1 || number > len(lines) {
+		return Line{}, fmt.Errorf("invalid line number %d, want 1 to %d", number, len(lines))
+	}
+	return lines[number-1], nil
+}
+
+// RowOf parses the line and returns a Row structure with positions of its column values.
+// Returns ErrEmptyLine if the line contains only whitespace and/or a comment.
+func (c *Conf) RowOf(line Line) (*Row, error) {
+	return c.parseLine(line.Text, line.Start)
+}
+
+// commentText returns the text of a comment-only line, without the leading comment character and
+// surrounding whitespace. The second return value is false if the line is not a comment-only line.
+func (c *Conf) commentText(line string) (string, bool) {
+	trimmed := strings.TrimLeft(line, c.params.Whitespace)
+	if trimmed == "" || []rune(trimmed)[0] != c.params.InlineComment {
+		return "", false
+	}
+	text := trimmed[len(string(c.params.InlineComment)):]
+	return strings.Trim(text, c.params.Whitespace), true
+}
+
+// CommentedRowOf parses the text after the comment character of a comment-only line (eg. a line
+// like #port = 5432) and returns a Row structure with positions of the column values found in it.
+// Returns ErrEmptyLine if the line is not a comment-only line or contains no columns.
+func (c *Conf) CommentedRowOf(line Line) (*Row, error) {
+	start := strings.IndexFunc(line.Text, func(r rune) bool {
+		return strings.IndexRune(c.params.Whitespace, r) == -1
+	})
+	if start == -1 || !strings.HasPrefix(line.Text[start:], string(c.params.InlineComment)) {
+		return nil, ErrEmptyLine
+	}
+	start += len(string(c.params.InlineComment))
+	return c.parseLine(line.Text[start:], line.Start+start)
+}
+
+// CommentOutLine inserts the comment character at the beginning of the line, after any
+// indentation, so that the line is ignored, while its content remains visible.
+// Positions of rows and lines after the start of the line become invalid.
+func (c *Conf) CommentOutLine(line Line) error {
+	if line.Start < 0 || line.End > len(c.conf) || line.Start > line.End {
+		return fmt.Errorf("invalid line %d", line.Number)
+	}
+	indent := len(line.Text) - len(strings.TrimLeft(line.Text, c.params.Whitespace))
+	pos := line.Start + indent
+	c.replace(pos, pos, string(c.params.InlineComment))
+	return nil
+}
+
+// UncommentLine removes the comment character (and a single space following it, if any) from
+// the beginning of a comment-only line, preserving any indentation before it.
+func (c *Conf) UncommentLine(line Line) error {
+	start := strings.IndexFunc(line.Text, func(r rune) bool {
+		return strings.IndexRune(c.params.Whitespace, r) == -1
+	})
+	if start == -1 || !strings.HasPrefix(line.Text[start:], string(c.params.InlineComment)) {
+		return fmt.Errorf("line %d is not a comment", line.Number)
+	}
+	end := start + len(string(c.params.InlineComment))
+	if strings.HasPrefix(line.Text[end:], " ") {
+		end++
+	}
+	c.replace(line.Start+start, line.Start+end, "")
+	return nil
+}
+
+// InsertRawLines inserts the given lines before the line, exactly as provided, without parsing,
+// quoting or reformatting them (like AppendRawLine). Positions of rows and lines at or after the
+// given line become invalid.
+func (c *Conf) InsertRawLines(line Line, lines ...string) error {
+	if line.Start < 0 || line.Start > len(c.conf) {
+		return fmt.Errorf("invalid line %d", line.Number)
+	}
+	text := strings.Join(lines, "\n") + "\n"
+	c.replace(line.Start, line.Start, text)
+	return nil
+}
+
+// RemoveLine removes the line from the configuration, including its EOL character.
+// Positions of rows and lines after the removed line become invalid.
+func (c *Conf) RemoveLine(line Line) error {
+	if line.Start < 0 || line.End > len(c.conf) || line.Start > line.End {
+		return fmt.Errorf("invalid line %d", line.Number)
+	}
+	c.replace(line.Start, line.End, "")
+	return nil
+}
+
+// CollapseBlankLines removes blank lines (lines containing only whitespace) that follow more than
+// max consecutive blank lines, so that no run of blank lines is longer than max.
+// Returns the number of lines removed.
+func (c *Conf) CollapseBlankLines(max int) int {
+	lines := c.Lines()
+	var remove []Line
+	run := 0
+	for _, line := range lines {
+		if strings.TrimSpace(line.Text) != "" {
+			run = 0
+			continue
+		}
+		run++
+		if run > max {
+			remove = append(remove, line)
+		}
+	}
+	// Remove lines in reverse order, so that positions of preceding lines remain valid
+	for i := len(remove) - 1; i >= 0; i-- {
+		c.replace(remove[i].Start, remove[i].End, "")
+	}
+	return len(remove)
+}
+
+// CommentMatching returns the text of every comment-only line that matches the regular expression,
+// along with the line number of the first matching comment. Useful for extracting metadata embedded
+// in comments (eg. "# last updated: 2018-01-01 10:00").
+// Returns ErrCommentNotFound if no comment matches.
+func (c *Conf) CommentMatching(re *regexp.Regexp) ([]string, int, error) {
+	var comments []string
+	var first int
+	for _, line := range c.Lines() {
+		text, ok := c.commentText(line.Text)
+		if !ok || !re.MatchString(text) {
+			continue
+		}
+		if first == 0 {
+			first = line.Number
+		}
+		comments = append(comments, text)
+	}
+	if len(comments) == 0 {
+		return nil, 0, ErrCommentNotFound
+	}
+	return comments, first, nil
+}
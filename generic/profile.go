@@ -0,0 +1,62 @@
+package generic
+
+import "strings"
+
+// utf8BOM is the byte order mark that some editors prepend to UTF-8 files.
+const utf8BOM = "\ufeff"
+
+// LineEnding is the character sequence used to terminate lines.
+type LineEnding string
+
+// Constants for line ending styles
+const (
+	NoLineEnding LineEnding = ""     // The configuration has no line endings (eg. a single line)
+	LF           LineEnding = "\n"   // Unix style
+	CRLF         LineEnding = "\r\n" // Windows style
+	CR           LineEnding = "\r"   // Classic Mac style
+)
+
+// String returns the name of the line ending style.
+func (e LineEnding) String() string {
+	switch e {
+	case LF:
+		return "LF"
+	case CRLF:
+		return "CRLF"
+	case CR:
+		return "CR"
+	}
+	return "none"
+}
+
+// Profile describes the encoding related properties of a configuration file.
+type Profile struct {
+	BOM         bool       // Whether the configuration starts with a UTF-8 byte order mark
+	LineEnding  LineEnding // The most frequently used line ending
+	EndsWithEOL bool       // Whether the last line is terminated with a line ending
+}
+
+// FileProfile reports whether the configuration starts with a UTF-8 BOM, which line ending
+// style is dominant and whether the configuration ends with a line ending. Tools can use it to
+// write changes back in the same style. On a tie between line ending styles, LF is preferred,
+// followed by CRLF.
+func (c *Conf) FileProfile() Profile {
+	crlf := strings.Count(c.conf, "\r\n")
+	lf := strings.Count(c.conf, "\n") - crlf
+	cr := strings.Count(c.conf, "\r") - crlf
+
+	var ending LineEnding
+	if lf > 0 && lf >= crlf && lf >= cr {
+		ending = LF
+	} else if crlf > 0 && crlf >= cr {
+		ending = CRLF
+	} else if cr > 0 {
+		ending = CR
+	}
+
+	return Profile{
+		BOM:         strings.HasPrefix(c.conf, utf8BOM),
+		LineEnding:  ending,
+		EndsWithEOL: strings.HasSuffix(c.conf, "\n") || strings.HasSuffix(c.conf, "\r"),
+	}
+}
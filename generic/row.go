@@ -27,12 +27,13 @@ func (t Token) ValueSize() (int, error) {
 
 // Row stores the starting and ending positions of column values found on this row as token objects.
 type Row struct {
-	tokens []Token
+	tokens  []Token
+	comment int // Position of the inline comment character, or -1 if the row has no inline comment
 }
 
 // newRow creates and initializes an empty Row structure
 func newRow() *Row {
-	r := &Row{}
+	r := &Row{comment: -1}
 	return r
 }
 
@@ -68,3 +69,18 @@ func (r *Row) Token(col int) (*Token, error) {
 	}
 	return &r.tokens[col], nil
 }
+
+// JoinColumns returns a copy of the row, in which the tokens of the column with the specified
+// index and all columns after it are merged into a single token, spanning the whitespace between
+// them. Useful for values that are allowed to contain unquoted whitespace. The row is returned
+// unchanged if it does not have more than one column starting at that index.
+func (r *Row) JoinColumns(col int) *Row {
+	if col < 0 || col >= len(r.tokens)-1 {
+		return r
+	}
+	joined := newRow()
+	joined.comment = r.comment
+	joined.tokens = append(joined.tokens, r.tokens[:col]...)
+	joined.addToken(r.tokens[col].Start, r.tokens[len(r.tokens)-1].End)
+	return joined
+}
@@ -0,0 +1,17 @@
+package generic
+
+import "fmt"
+
+// Warning describes a potential problem found in a configuration by a linter.
+type Warning struct {
+	Line    int    // 1-based number of the line the warning relates to, or 0 if it relates to the whole file
+	Message string // Human readable description of the problem
+}
+
+// String formats the warning, prefixed with the line number (if any).
+func (w Warning) String() string {
+	if w.Line == 0 {
+		return w.Message
+	}
+	return fmt.Sprintf("line %d: %s", w.Line, w.Message)
+}
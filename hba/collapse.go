@@ -0,0 +1,97 @@
+package hba
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+// network returns the network of the entry address, written either in CIDR notation
+// (eg. 10.0.0.0/8) or as an IP address with a separate mask column. Returns nil for host
+// names, keywords (eg. all or samenet) and rules of type local.
+func (e Entry) network() *net.IPNet {
+	if e.Mask == "" {
+		_, n, err := net.ParseCIDR(e.Address)
+		if err != nil {
+			return nil
+		}
+		return n
+	}
+	ip, mask := net.ParseIP(e.Address), net.ParseIP(e.Mask)
+	if ip == nil || mask == nil {
+		return nil
+	}
+	if ip4, mask4 := ip.To4(), mask.To4(); ip4 != nil && mask4 != nil {
+		ip, mask = ip4, mask4
+	}
+	if len(ip) != len(mask) {
+		return nil
+	}
+	m := net.IPMask(mask)
+	if ones, bits := m.Size(); ones == 0 && bits == 0 {
+		return nil // Non-canonical mask
+	}
+	return &net.IPNet{IP: ip.Mask(m), Mask: m}
+}
+
+// containsNetwork tests if the network a contains the whole network b.
+func containsNetwork(a, b *net.IPNet) bool {
+	aOnes, aBits := a.Mask.Size()
+	bOnes, bBits := b.Mask.Size()
+	return aBits == bBits && aOnes <= bOnes && a.Contains(b.IP)
+}
+
+// rawExceptAddress returns the raw columns of the rule, except its address and mask, along with
+// its options. Raw values are compared, so that a quoted name (eg. "all") is not mistaken for the
+// keyword it spells.
+func rawExceptAddress(r rule, e Entry) string {
+	columns := 4 // Type, database, user and address
+	if e.Mask != "" {
+		columns++
+	}
+	if len(r.base) < columns {
+		return ""
+	}
+	rest := append(append([]string(nil), r.base[:3]...), r.base[columns:]...)
+	return strings.Join(rest, "\x00") + "\x00" + joinOptions(r.options)
+}
+
+// CollapseAddressSupersets removes rules that are shadowed by an earlier rule, which is identical
+// except for its address, and whose network contains the whole network of the later rule (eg. a
+// rule for 10.0.0.5/32 after the same rule for 10.0.0.0/24). Columns are compared as written, so
+// quoted names (eg. "all") do not match the keywords they spell. As the earlier rule matches every
+// connection the later one would match, removing the later rule does not change effective access.
+// Only addresses in CIDR notation or with a separate mask column are compared, while rules with
+// host names or keywords (eg. all or samenet) are left alone. Returns the number of rules removed.
+func (c *Conf) CollapseAddressSupersets() (int, error) {
+	rules := c.rules()
+	keys := make([]string, len(rules))
+	networks := make([]*net.IPNet, len(rules))
+	for i, r := range rules {
+		e := c.entryOf(r)
+		keys[i] = rawExceptAddress(r, e)
+		networks[i] = e.network()
+	}
+
+	var removed []rule
+	for i := range rules {
+		if networks[i] == nil {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			if networks[j] != nil && keys[j] == keys[i] && containsNetwork(networks[j], networks[i]) {
+				removed = append(removed, rules[i])
+				break
+			}
+		}
+	}
+
+	// Remove lines in reverse order, so that positions of preceding lines remain valid
+	sort.Slice(removed, func(i, j int) bool { return removed[i].line.Start > removed[j].line.Start })
+	for _, r := range removed {
+		if err := c.RemoveLine(r.line); err != nil {
+			return 0, err
+		}
+	}
+	return len(removed), nil
+}
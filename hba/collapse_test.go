@@ -0,0 +1,98 @@
+package hba_test
+
+import (
+	"testing"
+
+	"github.com/quasoft/pgconf/hba"
+)
+
+func TestCollapseAddressSupersets(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		count   int
+	}{
+		{
+			"Host covered by preceding network",
+			"host\tall\tall\t10.0.0.0/24\tmd5\n" +
+				"local\tall\tall\t\tpeer\n" +
+				"host\tall\tall\t10.0.0.5/32\tmd5\n",
+			"host\tall\tall\t10.0.0.0/24\tmd5\n" +
+				"local\tall\tall\t\tpeer\n",
+			1,
+		},
+		{
+			"Separate mask column",
+			"host all all 10.0.0.0 255.0.0.0 md5\n" +
+				"host all all 10.1.0.0 255.255.0.0 md5\n",
+			"host all all 10.0.0.0 255.0.0.0 md5\n",
+			1,
+		},
+		{
+			"Narrower rule first",
+			"host all all 10.0.0.5/32 md5\n" +
+				"host all all 10.0.0.0/24 md5\n",
+			"host all all 10.0.0.5/32 md5\n" +
+				"host all all 10.0.0.0/24 md5\n",
+			0,
+		},
+		{
+			"Quoted name is not the keyword",
+			"host \"all\" all 10.0.0.0/24 md5\n" +
+				"host all all 10.0.0.5/32 md5\n",
+			"host \"all\" all 10.0.0.0/24 md5\n" +
+				"host all all 10.0.0.5/32 md5\n",
+			0,
+		},
+		{
+			"Different method",
+			"host all all 10.0.0.0/24 md5\n" +
+				"host all all 10.0.0.5/32 trust\n",
+			"host all all 10.0.0.0/24 md5\n" +
+				"host all all 10.0.0.5/32 trust\n",
+			0,
+		},
+		{
+			"Different options",
+			"hostssl all all 10.0.0.0/24 cert map=a\n" +
+				"hostssl all all 10.0.0.5/32 cert map=b\n",
+			"hostssl all all 10.0.0.0/24 cert map=a\n" +
+				"hostssl all all 10.0.0.5/32 cert map=b\n",
+			0,
+		},
+		{
+			"Disjoint networks",
+			"host all all 10.0.0.0/24 md5\n" +
+				"host all all 10.0.1.5/32 md5\n" +
+				"host all all ::1/128 md5\n",
+			"host all all 10.0.0.0/24 md5\n" +
+				"host all all 10.0.1.5/32 md5\n" +
+				"host all all ::1/128 md5\n",
+			0,
+		},
+		{
+			"Host names are left alone",
+			"host all all .example.com md5\n" +
+				"host all all db.example.com md5\n",
+			"host all all .example.com md5\n" +
+				"host all all db.example.com md5\n",
+			0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := hba.New(tt.content)
+			count, err := conf.CollapseAddressSupersets()
+			if err != nil {
+				t.Fatalf("CollapseAddressSupersets() errored with '%s', wanted no error", err)
+			}
+			if count != tt.count {
+				t.Errorf("CollapseAddressSupersets() = %d, want %d", count, tt.count)
+			}
+			if got := conf.All(); got != tt.want {
+				t.Errorf("CollapseAddressSupersets() changed configuration to %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package hba
+
+import (
+	"net"
+	"strings"
+)
+
+// Entry describes a single rule of pg_hba.conf with dequoted column values. As a quoted database
+// or user (eg. "all") is a literal name rather than the keyword it spells, DatabaseQuoted and
+// UserQuoted report whether the value was written in quotes.
+type Entry struct {
+	Type           string            `json:"type"`
+	Database       string            `json:"database"`
+	DatabaseQuoted bool              `json:"database_quoted,omitempty"`
+	User           string            `json:"user"`
+	UserQuoted     bool              `json:"user_quoted,omitempty"`
+	Address        string            `json:"address,omitempty"` // Empty for rules of type local
+	Mask           string            `json:"mask,omitempty"`    // IP mask, if written in a separate column
+	Method         string            `json:"method"`
+	Options        map[string]string `json:"options,omitempty"`
+	Line           int               `json:"line,omitempty"` // 1-based line number, or 0 if not read from a file
+}
+
+// Entries returns all rules in the file, in file order. Rules of type local have no address.
+// Options in the form name=value are returned in Options.
+func (c *Conf) Entries() []Entry {
+	var entries []Entry
+	for _, r := range c.rules() {
+		entries = append(entries, c.entryOf(r))
+	}
+	return entries
+}
+
+// entryOf returns the entry for the rule, with dequoted column values.
+func (c *Conf) entryOf(r rule) Entry {
+	base := make([]string, len(r.base))
+	quoted := make([]bool, len(r.base))
+	for i, value := range r.base {
+		base[i] = c.Dequote(value)
+		quoted[i] = base[i] != value
+	}
+
+	e := Entry{Line: r.line.Number}
+	if len(quoted) > 2 {
+		e.DatabaseQuoted, e.UserQuoted = quoted[1], quoted[2]
+	}
+	if len(base) > 0 {
+		e.Type = base[0]
+		base = base[1:]
+	}
+	if strings.ToLower(e.Type) != "local" {
+		// Insert the address after database and user, and an IP mask if there is one
+		if len(base) > 2 {
+			e.Address = base[2]
+			base = append(base[:2], base[3:]...)
+		}
+		if len(base) > 3 && net.ParseIP(base[2]) != nil {
+			e.Mask = base[2]
+			base = append(base[:2], base[3:]...)
+		}
+	}
+	fields := []*string{&e.Database, &e.User, &e.Method}
+	for i := range fields {
+		if i < len(base) {
+			*fields[i] = base[i]
+		}
+	}
+
+	for _, o := range r.options {
+		if e.Options == nil {
+			e.Options = make(map[string]string)
+		}
+		e.Options[o.name] = c.Dequote(o.value)
+	}
+	return e
+}
+
+// HasCatchAll tests if the final rule of the file is a broad rule of type host, which matches all
+// databases and all users, from any address (all, or a network like 0.0.0.0/0 or ::/0), eg. host
+// all all 0.0.0.0/0 reject. Tooling can use it to warn when connections not matched by earlier
+// rules may fall through to the implicit reject, which auditors may want stated explicitly. The
+// keywords all must be written unquoted, as quoted names ("all") match only a database or role
+// with that name. Earlier rules are not considered, so a broad rule followed by a narrower one
+// is not reported as a catch-all.
+func (c *Conf) HasCatchAll() bool {
+	rules := c.rules()
+	if len(rules) == 0 {
+		return false
+	}
+	r := rules[len(rules)-1]
+	if len(r.base) < 4 || r.base[0] != "host" || r.base[1] != "all" || r.base[2] != "all" {
+		return false
+	}
+	if r.base[3] == "all" {
+		return true
+	}
+	n := c.entryOf(r).network()
+	if n == nil {
+		return false
+	}
+	ones, _ := n.Mask.Size()
+	return ones == 0
+}
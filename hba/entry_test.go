@@ -0,0 +1,35 @@
+package hba_test
+
+import (
+	"testing"
+
+	"github.com/quasoft/pgconf/hba"
+)
+
+func TestHasCatchAll(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"Sample without catch-all", "local all all peer\nhost all all 127.0.0.1/32 md5\nhost all all ::1/128 md5\n", false},
+		{"IPv4 catch-all", "local all all peer\nhost all all 10.0.0.0/8 md5\nhost all all 0.0.0.0/0 reject\n", true},
+		{"IPv6 catch-all", "local all all peer\nhost all all ::/0 reject\n", true},
+		{"Address keyword all", "local all all peer\nhost all all 127.0.0.1/32 md5\nhost all all all reject # deny everything else\n", true},
+		{"Netmask in separate column", "local all all peer\nhost all all 0.0.0.0 0.0.0.0 reject\n", true},
+		{"SSL only", "local all all peer\nhostssl all all all scram-sha-256\n", false},
+		{"Quoted names", "local all all peer\nhost \"all\" \"all\" 0.0.0.0/0 reject\n", false},
+		{"Catch-all not last", "local all all peer\nhost all all all reject\nhost all all 10.0.0.0/8 md5\n", false},
+		{"Specific user", "local all all peer\nhost all postgres all reject\n", false},
+		{"Specific network", "local all all peer\nhost all all 10.0.0.0/8 reject\n", false},
+		{"Local only", "local all all peer\n", false},
+		{"No rules", "# empty\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hba.New(tt.content).HasCatchAll(); got != tt.want {
+				t.Errorf("HasCatchAll() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
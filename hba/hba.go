@@ -30,6 +30,8 @@ var ErrEmptyArgument = errors.New("empty argument")
 //  - DefaultQuote:           "
 //  - InlineComment:          #
 //  - AlwaysQuoteStrings:	  false
+//  - StrictColumns:          false
+//  - GroupDelims:            none
 func NewParams() generic.Params {
 	return generic.Params{
 		Whitespace:             " \t\r",
@@ -39,29 +41,31 @@ func NewParams() generic.Params {
 		DefaultQuote:           '"',
 		InlineComment:          '#',
 		AlwaysQuoteStrings:     false,
+		StrictColumns:          false,
+		GroupDelims:            "",
 	}
 }
 
 // Conf represents configuration file for host-based authentication of PostgreSQL (pg_hba.conf).
 type Conf struct {
 	*generic.Conf
+	applyMode ApplyMode
 }
 
 // New creates a new structure for reading/writing to pg_hba.conf files with default params (see NewParams).
 func New(conf string) *Conf {
 	return &Conf{
-		generic.New(conf, NewParams()),
+		Conf: generic.New(conf, NewParams()),
 	}
 }
 
 // Open opens and reads configuration from a file.
 func Open(filename string) (*Conf, error) {
-	content, err := ioutil.ReadFile(filename)
+	conf, err := generic.Open(filename, NewParams())
 	if err != nil {
-		return nil, fmt.Errorf("could not read file %s: %s", filename, err)
+		return nil, err
 	}
-	conf := string(content)
-	return New(conf), nil
+	return &Conf{Conf: conf}, nil
 }
 
 // OpenReader reads configuration from a reader.
@@ -74,6 +78,26 @@ func OpenReader(r io.Reader) (*Conf, error) {
 	return New(conf), nil
 }
 
+// OpenLimit opens and reads configuration from a file, failing with generic.ErrLimitExceeded
+// if the file is larger than maxBytes.
+func OpenLimit(filename string, maxBytes int64) (*Conf, error) {
+	conf, err := generic.OpenLimit(filename, maxBytes, NewParams())
+	if err != nil {
+		return nil, err
+	}
+	return &Conf{Conf: conf}, nil
+}
+
+// OpenReaderLimit reads configuration from a reader, failing with generic.ErrLimitExceeded
+// if the reader returns more than maxBytes.
+func OpenReaderLimit(r io.Reader, maxBytes int64) (*Conf, error) {
+	conf, err := generic.ReadAllLimit(r, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	return New(conf), nil
+}
+
 // LookupFirst searches for a line that contains the given key, and if found,
 // returns a Row structure for that line.
 func (c *Conf) LookupFirst(keyCol int, key string) (*generic.Row, error) {
@@ -105,7 +129,8 @@ func (c *Conf) LookupAll(keyCol int, key string) ([]*generic.Row, error) {
 }
 
 // AppendEntry adds a new row with the given values and returns a Row
-// structure describing the line appended.
+// structure describing the line appended. Values are written as-is, so keywords like all or
+// replication keep their special meaning (see AddEntry for literal database and user names).
 func (c *Conf) AppendEntry(connType, database, user, address, method string) (*generic.Row, error) {
 	isSpace := func(value string) bool {
 		return strings.TrimSpace(value) == ""
@@ -115,3 +140,46 @@ func (c *Conf) AppendEntry(connType, database, user, address, method string) (*g
 	}
 	return c.Append(connType, database, user, address, method)
 }
+
+// UpdateEntryAligned replaces the value of the column at the given row (quoting it if necessary),
+// and re-pads the whitespace after it, so that the following columns keep their original
+// starting position where possible. Useful for keeping manually aligned files tidy.
+func (c *Conf) UpdateEntryAligned(row *generic.Row, col int, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return ErrEmptyArgument
+	}
+	return c.SetStringAligned(row, col, value)
+}
+
+// keywords are the values with a special meaning in the database and user columns.
+var keywords = []string{"all", "sameuser", "samerole", "samegroup", "replication"}
+
+// QuoteName returns the database or user name enclosed in double quotes, if it would otherwise
+// not be treated literally by PostgreSQL: if it is a keyword (eg. all or replication), contains
+// whitespace, quotes, commas, equal signs or comment characters, or starts with + or @.
+// Ordinary names are returned unchanged.
+func (c *Conf) QuoteName(name string) string {
+	quote := name == "" ||
+		c.HasQuotesOrWhitespace(name) ||
+		strings.ContainsAny(name, ",=#") ||
+		strings.HasPrefix(name, "+") ||
+		strings.HasPrefix(name, "@")
+	for _, k := range keywords {
+		if name == k {
+			quote = true
+		}
+	}
+	if !quote {
+		return name
+	}
+	return c.Quote(name)
+}
+
+// AddEntry adds a new row like AppendEntry, but treats the database and user as literal names,
+// quoting them with QuoteName if necessary (eg. a user named all is written as "all").
+func (c *Conf) AddEntry(connType, database, user, address, method string) (*generic.Row, error) {
+	if strings.TrimSpace(database) == "" || strings.TrimSpace(user) == "" {
+		return nil, ErrEmptyArgument
+	}
+	return c.AppendEntry(connType, c.QuoteName(database), c.QuoteName(user), address, method)
+}
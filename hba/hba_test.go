@@ -139,6 +139,43 @@ func TestString(t *testing.T) {
 	}
 }
 
+func TestColumnRaw(t *testing.T) {
+	conf := hba.New("hostssl all\t\tall  10.0.0.0/8\t cert   map=a \tclientcert=verify-full\tldapprefix=\"cn= \" # comment\n")
+	row, err := conf.LookupFirst(hba.ConnType, "hostssl")
+	if err != nil {
+		t.Fatalf(`LookupFirst(hba.ConnType, "hostssl") errored with '%s', wanted no error`, err)
+	}
+
+	tests := []struct {
+		name    string
+		col     int
+		want    string
+		noerror bool
+	}{
+		{"Get Method", hba.Method, "cert", true},
+		{"Get first option", 5, "map=a", true},
+		{"Get second option", 6, "clientcert=verify-full", true},
+		{"Get quoted option", 7, `ldapprefix="cn= "`, true},
+		{"Get column after last", 8, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := conf.ColumnRaw(row, tt.col)
+			if err != nil && tt.noerror {
+				t.Errorf("ColumnRaw(row, %d) errored with '%s', wanted no error", tt.col, err)
+			} else if err == nil && !tt.noerror {
+				t.Errorf("ColumnRaw(row, %d) did not error, wanted error", tt.col)
+			} else if got != tt.want {
+				t.Errorf("ColumnRaw(row, %d) = %q, want %q", tt.col, got, tt.want)
+			}
+		})
+	}
+
+	if got, err := conf.String(row, 6); err != nil || got != "clientcert=verify-full" {
+		t.Errorf("String(row, 6) = %q, %v, want %q, no error", got, err, "clientcert=verify-full")
+	}
+}
+
 func TestAppendEntry(t *testing.T) {
 	conf := openTestFile(t, "sample.conf")
 
@@ -153,3 +190,136 @@ func TestAppendEntry(t *testing.T) {
 		t.Errorf("AppendEntry() = failed to append a new row")
 	}
 }
+
+func TestUpdateEntryAligned(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		col     int
+		value   string
+		want    string
+	}{
+		{
+			"Longer method, spaces",
+			"host    all             all             127.0.0.1/32            md5     clientcert=verify-ca\n",
+			hba.Method, "scram-sha-256",
+			"host    all             all             127.0.0.1/32            scram-sha-256 clientcert=verify-ca\n",
+		},
+		{
+			"Slightly longer method, spaces",
+			"host    all             all             127.0.0.1/32            md5         clientcert=verify-ca\n",
+			hba.Method, "cert",
+			"host    all             all             127.0.0.1/32            cert        clientcert=verify-ca\n",
+		},
+		{
+			"Shorter address, spaces",
+			"host    all             all             127.0.0.1/32            md5\n",
+			hba.Address, "::1/128",
+			"host    all             all             ::1/128                 md5\n",
+		},
+		{
+			"Longer user, tabs",
+			"host\tall\tbob\t127.0.0.1/32\tmd5\n",
+			hba.User, "replicator",
+			"host\tall\treplicator\t127.0.0.1/32\tmd5\n",
+		},
+		{
+			"Longer user, tabs keep alignment",
+			"host\tall\tbob\t\t127.0.0.1/32\tmd5\n",
+			hba.User, "replicator",
+			"host\tall\treplicator\t127.0.0.1/32\tmd5\n",
+		},
+		{
+			"Last column",
+			"host    all             all             127.0.0.1/32            md5\n",
+			hba.Method, "scram-sha-256",
+			"host    all             all             127.0.0.1/32            scram-sha-256\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := hba.New(tt.content)
+			row, err := conf.LookupFirst(hba.ConnType, "host")
+			if err != nil {
+				t.Fatalf("LookupFirst() errored with '%s', wanted no error", err)
+			}
+			if err := conf.UpdateEntryAligned(row, tt.col, tt.value); err != nil {
+				t.Fatalf("UpdateEntryAligned(row, %d, %q) errored with '%s', wanted no error", tt.col, tt.value, err)
+			}
+			if got := conf.All(); got != tt.want {
+				t.Errorf("UpdateEntryAligned(row, %d, %q) = %q, want %q", tt.col, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateEntryAligned_RoundTripQuotes(t *testing.T) {
+	values := []string{
+		`it's a "quote"`,
+		`'"`,
+		`""''`,
+		`back\slash`,
+		`c:\`,
+		`a\'b\"c`,
+	}
+	for _, value := range values {
+		t.Run(value, func(t *testing.T) {
+			conf := hba.New("host all all ::1/128 md5\n")
+			row, err := conf.LookupFirst(hba.ConnType, "host")
+			if err != nil {
+				t.Fatalf(`LookupFirst(hba.ConnType, "host") errored with '%s', wanted no error`, err)
+			}
+			if err := conf.UpdateEntryAligned(row, hba.Database, value); err != nil {
+				t.Fatalf("UpdateEntryAligned(%q) errored with '%s', wanted no error", value, err)
+			}
+			row, err = conf.LookupFirst(hba.ConnType, "host")
+			if err != nil {
+				t.Fatalf(`LookupFirst(hba.ConnType, "host") errored with '%s', wanted no error`, err)
+			}
+			got, err := conf.String(row, hba.Database)
+			if err != nil {
+				t.Fatalf("String() errored with '%s', wanted no error", err)
+			}
+			if got != value {
+				t.Errorf("String() = %q after UpdateEntryAligned(%q) wrote %q, want %q", got, value, conf.All(), value)
+			}
+		})
+	}
+}
+
+func TestQuoteName(t *testing.T) {
+	conf := hba.New("")
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"postgres", "postgres"},
+		{"all", `"all"`},
+		{"replication", `"replication"`},
+		{"ALL", "ALL"},
+		{"my db", `"my db"`},
+		{"a,b", `"a,b"`},
+		{"+admins", `"+admins"`},
+		{"@users", `"@users"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := conf.QuoteName(tt.name); got != tt.want {
+				t.Errorf("QuoteName(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddEntry(t *testing.T) {
+	conf := hba.New("")
+	if _, err := conf.AddEntry("host", "sales", "all", "10.0.0.0/8", "md5"); err != nil {
+		t.Fatalf("AddEntry() errored with '%s', wanted no error", err)
+	}
+	if got, want := conf.All(), "host\tsales\t\"all\"\t10.0.0.0/8\tmd5"; got != want {
+		t.Errorf("AddEntry() changed configuration to %q, want %q", got, want)
+	}
+	if _, err := conf.AddEntry("host", "", "postgres", "10.0.0.0/8", "md5"); err != hba.ErrEmptyArgument {
+		t.Errorf("AddEntry() with empty database errored with '%v', want '%s'", err, hba.ErrEmptyArgument)
+	}
+}
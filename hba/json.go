@@ -0,0 +1,118 @@
+package hba
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ApplyMode defines how ApplyJSON treats rules that already exist in the file.
+type ApplyMode int
+
+// Constants for modes of ApplyJSON
+const (
+	// ApplyAppend appends the new rules after the existing ones.
+	ApplyAppend ApplyMode = iota
+	// ApplyReplace removes all existing rules (but not comments and blank lines) before appending the new ones.
+	ApplyReplace
+)
+
+// MarshalJSON returns the rules in the file as a JSON array of entry objects (see Entry).
+func (c *Conf) MarshalJSON() ([]byte, error) {
+	entries := c.Entries()
+	if entries == nil {
+		entries = []Entry{}
+	}
+	return json.Marshal(entries)
+}
+
+// SetApplyMode sets how ApplyJSON treats existing rules. Defaults to ApplyAppend.
+func (c *Conf) SetApplyMode(mode ApplyMode) {
+	c.applyMode = mode
+}
+
+// ApplyJSON parses a JSON array of entry objects (in the format produced by MarshalJSON) and
+// writes them as rules, either after the existing rules or in place of them, depending on the
+// mode set with SetApplyMode. The line field of entries is ignored. Every entry must have a type,
+// database, user and method, as well as an address unless the type is local. Names with
+// database_quoted or user_quoted set are written in quotes, as literal names. Entries are
+// validated before the file is changed, so that an invalid payload leaves the file untouched.
+func (c *Conf) ApplyJSON(data []byte) error {
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("could not parse JSON entries: %s", err)
+	}
+	lines := make([][]string, len(entries))
+	for i, e := range entries {
+		columns, err := c.entryColumns(e)
+		if err != nil {
+			return fmt.Errorf("invalid entry %d: %s", i+1, err)
+		}
+		lines[i] = columns
+	}
+
+	if c.applyMode == ApplyReplace {
+		rules := c.rules()
+		// Remove rules in reverse order, so that positions of preceding lines remain valid
+		for i := len(rules) - 1; i >= 0; i-- {
+			if err := c.RemoveLine(rules[i].line); err != nil {
+				return err
+			}
+		}
+	}
+	for _, columns := range lines {
+		if _, err := c.Append(columns...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entryColumns validates the entry and returns the raw column values of the rule for it.
+func (c *Conf) entryColumns(e Entry) ([]string, error) {
+	isSpace := func(value string) bool {
+		return strings.TrimSpace(value) == ""
+	}
+	isLocal := strings.ToLower(e.Type) == "local"
+	if isSpace(e.Type) || isSpace(e.Database) || isSpace(e.User) || isSpace(e.Method) || (!isLocal && isSpace(e.Address)) {
+		return nil, ErrEmptyArgument
+	}
+
+	columns := []string{e.Type, c.quoteName(e.Database, e.DatabaseQuoted), c.quoteName(e.User, e.UserQuoted)}
+	if !isLocal {
+		columns = append(columns, c.quoteValue(e.Address))
+		if e.Mask != "" {
+			columns = append(columns, e.Mask)
+		}
+	}
+	columns = append(columns, e.Method)
+
+	names := make([]string, 0, len(e.Options))
+	for name := range e.Options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		columns = append(columns, name+"="+c.quoteValue(e.Options[name]))
+	}
+	return columns, nil
+}
+
+// quoteName quotes the database or user name if it was quoted in the exported entry, so that a
+// literal name (eg. "all") is not written back as the keyword it spells, or otherwise if it
+// contains quotes or whitespace.
+func (c *Conf) quoteName(name string, quoted bool) string {
+	if quoted {
+		return c.Quote(name)
+	}
+	return c.quoteValue(name)
+}
+
+// quoteValue quotes the value if it contains quotes or whitespace.
+func (c *Conf) quoteValue(value string) string {
+	if c.HasQuotesOrWhitespace(value) {
+		return c.Quote(value)
+	}
+	return value
+}
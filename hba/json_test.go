@@ -0,0 +1,125 @@
+package hba_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/quasoft/pgconf/hba"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	conf := openTestFile(t, "sample.conf")
+	if _, err := conf.AppendEntry("local", "all", "postgres", "peer", "map=admins"); err != nil {
+		t.Fatalf("AppendEntry() errored with '%s', wanted no error", err)
+	}
+	if _, err := conf.Append("hostssl", `"my db"`, "all", "192.168.0.0", "255.255.0.0", "cert", "clientcert=verify-full"); err != nil {
+		t.Fatalf("Append() errored with '%s', wanted no error", err)
+	}
+
+	got, err := json.Marshal(conf)
+	if err != nil {
+		t.Fatalf("Marshal() errored with '%s', wanted no error", err)
+	}
+	want := strings.Join([]string{
+		`[{"type":"host","database":"all","user":"all","address":"127.0.0.1/32","method":"md5","line":80}`,
+		`{"type":"host","database":"all","user":"all","address":"::1/128","method":"md5","line":82}`,
+		`{"type":"host","database":"replication","user":"postgres","address":"127.0.0.1/32","method":"md5","line":85}`,
+		`{"type":"host","database":"replication","user":"postgres","address":"::1/128","method":"md5","line":86}`,
+		`{"type":"host","database":"replication","user":"postgres","address":"10.0.0.3/32","method":"md5","line":87}`,
+		`{"type":"local","database":"all","user":"postgres","method":"peer","options":{"map":"admins"},"line":88}`,
+		`{"type":"hostssl","database":"my db","database_quoted":true,"user":"all","address":"192.168.0.0","mask":"255.255.0.0","method":"cert","options":{"clientcert":"verify-full"},"line":89}]`,
+	}, ",")
+	if string(got) != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+
+	empty, err := json.Marshal(hba.New("# no rules\n"))
+	if err != nil {
+		t.Fatalf("Marshal() errored with '%s', wanted no error", err)
+	}
+	if string(empty) != "[]" {
+		t.Errorf("Marshal() of a file without rules = %s, want []", empty)
+	}
+
+	quoted, err := json.Marshal(hba.New("host\t\"all\"\tbob\t10.0.0.0/8\tmd5\nhost\tall\t\"bob\"\t10.0.0.0/8\tmd5\n"))
+	if err != nil {
+		t.Fatalf("Marshal() errored with '%s', wanted no error", err)
+	}
+	want = strings.Join([]string{
+		`[{"type":"host","database":"all","database_quoted":true,"user":"bob","address":"10.0.0.0/8","method":"md5","line":1}`,
+		`{"type":"host","database":"all","user":"bob","user_quoted":true,"address":"10.0.0.0/8","method":"md5","line":2}]`,
+	}, ",")
+	if string(quoted) != want {
+		t.Errorf("Marshal() of quoted names = %s, want %s", quoted, want)
+	}
+}
+
+func TestApplyJSON(t *testing.T) {
+	payload := []byte(`[
+		{"type": "host", "database": "all", "user": "all", "address": "10.0.0.0/8", "method": "scram-sha-256"},
+		{"type": "local", "database": "my db", "user": "postgres", "method": "peer", "options": {"map": "admins"}}
+	]`)
+	content := "# Rules\nhost\tall\tall\t127.0.0.1/32\tmd5\n"
+
+	tests := []struct {
+		name string
+		mode hba.ApplyMode
+		want string
+	}{
+		{
+			"Append",
+			hba.ApplyAppend,
+			content +
+				"host\tall\tall\t10.0.0.0/8\tscram-sha-256\n" +
+				"local\t\"my db\"\tpostgres\tpeer\tmap=admins",
+		},
+		{
+			"Replace",
+			hba.ApplyReplace,
+			"# Rules\n" +
+				"host\tall\tall\t10.0.0.0/8\tscram-sha-256\n" +
+				"local\t\"my db\"\tpostgres\tpeer\tmap=admins",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := hba.New(content)
+			conf.SetApplyMode(tt.mode)
+			if err := conf.ApplyJSON(payload); err != nil {
+				t.Fatalf("ApplyJSON() errored with '%s', wanted no error", err)
+			}
+			if got := conf.All(); got != tt.want {
+				t.Errorf("ApplyJSON() changed configuration to %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	roundTrip := "host\t\"all\"\t\"replication\"\t10.0.0.0/8\tmd5\nhost\tall\treplication\t10.0.0.0/8\tmd5"
+	data, err := json.Marshal(hba.New(roundTrip))
+	if err != nil {
+		t.Fatalf("Marshal() errored with '%s', wanted no error", err)
+	}
+	conf := hba.New("")
+	if err := conf.ApplyJSON(data); err != nil {
+		t.Fatalf("ApplyJSON() errored with '%s', wanted no error", err)
+	}
+	if got := conf.All(); got != roundTrip {
+		t.Errorf("ApplyJSON() of exported quoted names changed configuration to %q, want %q", got, roundTrip)
+	}
+
+	invalid := []string{
+		`{"type": "host"}`,
+		`[{"type": "host", "database": "all", "user": "all", "method": "md5"}]`,
+		`[{"type": "local", "database": "all", "user": "", "method": "peer"}]`,
+	}
+	for _, data := range invalid {
+		conf := hba.New(content)
+		if err := conf.ApplyJSON([]byte(data)); err == nil {
+			t.Errorf("ApplyJSON(%s) did not error, wanted error", data)
+		}
+		if conf.All() != content {
+			t.Errorf("ApplyJSON(%s) changed configuration to %q, want it unchanged", data, conf.All())
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package hba
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/quasoft/pgconf/generic"
+)
+
+// LintColumns returns a warning for every rule that does not have exactly the expected number
+// of base columns (usually 5: type, database, user, address and method). Options in the form
+// name=value are not counted. Rules of type local are expected to have one column less, as they
+// have no address, while rules with an IP address and a separate netmask column (eg. 10.0.0.0
+// 255.0.0.0) are expected to have one column more. Useful for catching rules where a value was
+// split into several columns (eg. by an unquoted tab) or where a column is missing.
+func (c *Conf) LintColumns(expected int) []generic.Warning {
+	var warnings []generic.Warning
+	for _, line := range c.Lines() {
+		row, err := c.RowOf(line)
+		if err != nil {
+			continue
+		}
+
+		var base []string
+		for col := 0; col < row.ColCount(); col++ {
+			value, err := c.Raw(row, col)
+			if err == nil && !isOption(value) {
+				base = append(base, value)
+			}
+		}
+
+		want := expected
+		if connType, err := c.String(row, ConnType); err == nil && strings.ToLower(connType) == "local" {
+			want--
+		} else if len(base) > Address+1 && isNetmask(base[Address], base[Address+1]) {
+			want++
+		}
+		if len(base) != want {
+			warnings = append(warnings, generic.Warning{
+				Line:    line.Number,
+				Message: fmt.Sprintf("rule has %d columns, want %d", len(base), want),
+			})
+		}
+	}
+	return warnings
+}
+
+// isOption tests if the raw column value is an authentication option (eg. clientcert=1).
+func isOption(value string) bool {
+	i := strings.IndexRune(value, '=')
+	return i > 0 && !strings.ContainsAny(value[:i], `"'`)
+}
+
+// isNetmask tests if the raw column values are an IP address without a prefix length, followed
+// by a netmask of the same address family (eg. 10.0.0.0 255.0.0.0).
+func isNetmask(address, mask string) bool {
+	ip, m := net.ParseIP(address), net.ParseIP(mask)
+	if ip == nil || m == nil || (ip.To4() == nil) != (m.To4() == nil) {
+		return false
+	}
+	if m4 := m.To4(); m4 != nil {
+		m = m4
+	}
+	_, bits := net.IPMask(m).Size()
+	return bits != 0
+}
@@ -0,0 +1,58 @@
+package hba_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasoft/pgconf/generic"
+	"github.com/quasoft/pgconf/hba"
+)
+
+func TestLintColumns(t *testing.T) {
+	conf := hba.New("# TYPE  DATABASE  USER  ADDRESS  METHOD\n" +
+		"local   all       all                md5\n" +
+		"host    all       all   10.0.0.0/8\n" +
+		"host    all       all   ::1/128     cert    clientcert=verify-full\n" +
+		"host    all       all   127.0.0.1/32  md5\n" +
+		"host    all       all   10.0.0.0  255.0.0.0  md5\n" +
+		"host    all       all   10.0.0.0  255.0.0.0\n" +
+		"host    all       all   10.0.0.0  md5  extra\n")
+
+	got := conf.LintColumns(5)
+	want := []generic.Warning{
+		{Line: 3, Message: "rule has 4 columns, want 5"},
+		{Line: 7, Message: "rule has 5 columns, want 6"},
+		{Line: 8, Message: "rule has 6 columns, want 5"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LintColumns(5) = %v, want %v", got, want)
+	}
+
+	if got := openTestFile(t, "sample.conf").LintColumns(5); len(got) != 0 {
+		t.Errorf("LintColumns(5) = %v for sample.conf, want no warnings", got)
+	}
+}
+
+func TestStrictColumns(t *testing.T) {
+	conf := hba.New("host all all 127.0.0.1/32 md5\n")
+	params := conf.Params()
+	params.StrictColumns = true
+	conf.SetParams(params)
+
+	if _, err := conf.AppendEntry("host", "all", "all\tother", "::1/128", "md5"); err == nil {
+		t.Errorf("AppendEntry() with an unquoted tab did not error, wanted error")
+	}
+	row, err := conf.LookupFirst(hba.ConnType, "host")
+	if err != nil {
+		t.Fatalf("LookupFirst() errored with '%s', wanted no error", err)
+	}
+	if err := conf.SetRaw(row, hba.Method, "md5\tpam"); err == nil {
+		t.Errorf("SetRaw() with an unquoted tab did not error, wanted error")
+	}
+	if err := conf.SetRaw(row, hba.Method, "scram-sha-256"); err != nil {
+		t.Errorf("SetRaw() errored with '%s', wanted no error", err)
+	}
+	if conf.All() != "host all all 127.0.0.1/32 scram-sha-256\n" {
+		t.Errorf("All() = %q, want %q", conf.All(), "host all all 127.0.0.1/32 scram-sha-256\n")
+	}
+}
@@ -0,0 +1,182 @@
+package hba
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/quasoft/pgconf/generic"
+)
+
+// option is an authentication option of a rule in the form name=value, with the value kept raw.
+type option struct {
+	name  string
+	value string
+}
+
+// rule describes the columns of a single line with a rule.
+type rule struct {
+	line      generic.Line
+	row       *generic.Row
+	base      []string // Raw values of the base columns (type, database, user, address and method)
+	options   []option // Options in the order they appear on the line
+	optionCol int      // Index of the column with the first option, or -1 if there are no options
+}
+
+// rules returns all lines with a rule, in file order.
+func (c *Conf) rules() []rule {
+	var result []rule
+	for _, line := range c.Lines() {
+		row, err := c.RowOf(line)
+		if err != nil {
+			continue
+		}
+		r := c.ruleOf(row)
+		r.line = line
+		result = append(result, r)
+	}
+	return result
+}
+
+// ruleOf splits the columns of the row into base columns and options.
+func (c *Conf) ruleOf(row *generic.Row) rule {
+	r := rule{row: row, optionCol: -1}
+	for col := 0; col < row.ColCount(); col++ {
+		value, err := c.Raw(row, col)
+		if err != nil {
+			continue
+		}
+		if !isOption(value) {
+			r.base = append(r.base, value)
+			continue
+		}
+		if r.optionCol == -1 {
+			r.optionCol = col
+		}
+		i := strings.IndexRune(value, '=')
+		r.options = append(r.options, option{value[:i], value[i+1:]})
+	}
+	return r
+}
+
+// mergeOptions merges the options of src into dst. Values from src win on name conflict, while
+// new names are added after existing ones.
+func mergeOptions(dst, src []option) []option {
+	result := append([]option(nil), dst...)
+	for _, o := range src {
+		found := false
+		for i := range result {
+			if result[i].name == o.name {
+				result[i].value = o.value
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// joinOptions formats options as space separated name=value pairs.
+func joinOptions(options []option) string {
+	pairs := make([]string, len(options))
+	for i, o := range options {
+		pairs[i] = o.name + "=" + o.value
+	}
+	return strings.Join(pairs, " ")
+}
+
+// MergeRuleOptions finds rules with identical base columns (type, database, user, address and
+// method), but different options, and merges them into the first such rule. Options of later
+// rules win on name conflict. Later rules are removed. Rules that are exact duplicates are
+// left alone. Returns the number of rules merged into another rule.
+func (c *Conf) MergeRuleOptions() (int, error) {
+	rules := c.rules()
+
+	// Group rules by base columns, keeping the order of first appearance
+	groups := make(map[string][]int)
+	var keys []string
+	for i, r := range rules {
+		key := strings.Join(r.base, "\x00")
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+
+	type edit struct {
+		pos   int
+		apply func() error
+	}
+	var edits []edit
+	count := 0
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 || !optionsDiffer(rules, group) {
+			continue
+		}
+
+		first := rules[group[0]]
+		merged := first.options
+		for _, i := range group[1:] {
+			merged = mergeOptions(merged, rules[i].options)
+			line := rules[i].line
+			edits = append(edits, edit{line.Start, func() error { return c.RemoveLine(line) }})
+			count++
+		}
+
+		if joinOptions(merged) != joinOptions(first.options) {
+			text := joinOptions(merged)
+			edits = append(edits, edit{first.line.Start, func() error {
+				if first.optionCol != -1 {
+					return c.SetRawRest(first.row, first.optionCol, text)
+				}
+				col := first.row.ColCount() - 1
+				last, err := c.Raw(first.row, col)
+				if err != nil {
+					return err
+				}
+				return c.SetRawRest(first.row, col, last+c.Params().DefaultDelim+text)
+			}})
+		}
+	}
+
+	// Apply edits in reverse order, so that positions of preceding lines remain valid
+	sort.Slice(edits, func(i, j int) bool { return edits[i].pos > edits[j].pos })
+	for _, e := range edits {
+		if err := e.apply(); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// optionsDiffer tests if the rules with the given indexes do not all have the same options.
+func optionsDiffer(rules []rule, group []int) bool {
+	want := joinOptions(rules[group[0]].options)
+	for _, i := range group[1:] {
+		if joinOptions(rules[i].options) != want {
+			return true
+		}
+	}
+	return false
+}
+
+// MethodOptions returns the authentication method of the rule at the given row, along with its
+// dequoted options (eg. ldapserver=ldap.example.com for the ldap method). The options map is
+// empty if the rule has no options. Returns an error if the rule has no method.
+func (c *Conf) MethodOptions(row *generic.Row) (string, map[string]string, error) {
+	if row == nil {
+		return "", nil, errors.New("could not retrieve method options for a nil row")
+	}
+	e := c.entryOf(c.ruleOf(row))
+	if e.Method == "" {
+		return "", nil, errors.New("rule has no method")
+	}
+	if e.Options == nil {
+		e.Options = make(map[string]string)
+	}
+	return e.Method, e.Options, nil
+}
@@ -0,0 +1,110 @@
+package hba_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasoft/pgconf/hba"
+)
+
+func TestMergeRuleOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		count   int
+	}{
+		{
+			"Two rules with different options",
+			"# TYPE\tDATABASE\tUSER\tADDRESS\t\tMETHOD\n" +
+				"hostssl\tall\t\tall\t10.0.0.0/8\tcert\tmap=a\n" +
+				"host\tall\t\tall\t127.0.0.1/32\tmd5\n" +
+				"hostssl\tall\t\tall\t10.0.0.0/8\tcert\tclientcert=1\n",
+			"# TYPE\tDATABASE\tUSER\tADDRESS\t\tMETHOD\n" +
+				"hostssl\tall\t\tall\t10.0.0.0/8\tcert\tmap=a clientcert=1\n" +
+				"host\tall\t\tall\t127.0.0.1/32\tmd5\n",
+			1,
+		},
+		{
+			"Later value wins",
+			"host all all 10.0.0.0/8 ldap ldapserver=a ldapport=389 # primary\n" +
+				"host all all 10.0.0.0/8 ldap ldapserver=b\n",
+			"host all all 10.0.0.0/8 ldap ldapserver=b ldapport=389 # primary\n",
+			1,
+		},
+		{
+			"First rule without options",
+			"host all all 10.0.0.0/8 cert\n" +
+				"host all all 10.0.0.0/8 cert map=a\n",
+			"host all all 10.0.0.0/8 cert\tmap=a\n",
+			1,
+		},
+		{
+			"Exact duplicates are left alone",
+			"host all all 10.0.0.0/8 cert map=a\n" +
+				"host all all 10.0.0.0/8 cert map=a\n",
+			"host all all 10.0.0.0/8 cert map=a\n" +
+				"host all all 10.0.0.0/8 cert map=a\n",
+			0,
+		},
+		{
+			"Different base columns",
+			"host all all 10.0.0.0/8 cert map=a\n" +
+				"host all all 10.0.0.0/16 cert clientcert=1\n",
+			"host all all 10.0.0.0/8 cert map=a\n" +
+				"host all all 10.0.0.0/16 cert clientcert=1\n",
+			0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := hba.New(tt.content)
+			count, err := conf.MergeRuleOptions()
+			if err != nil {
+				t.Fatalf("MergeRuleOptions() errored with '%s', wanted no error", err)
+			}
+			if count != tt.count {
+				t.Errorf("MergeRuleOptions() = %d, want %d", count, tt.count)
+			}
+			if got := conf.All(); got != tt.want {
+				t.Errorf("MergeRuleOptions() changed configuration to %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMethodOptions(t *testing.T) {
+	conf := hba.New("# LDAP\n" +
+		`host all all 10.0.0.0/8 ldap ldapserver=ldap.example.com ldapport=389 ldapprefix="cn=" ldapsuffix=", dc=example, dc=com"` + "\n" +
+		"local all postgres peer\n")
+
+	row, err := conf.LookupFirst(hba.Method, "ldap")
+	if err != nil {
+		t.Fatalf("LookupFirst() errored with '%s', wanted no error", err)
+	}
+	method, options, err := conf.MethodOptions(row)
+	if err != nil {
+		t.Fatalf("MethodOptions() errored with '%s', wanted no error", err)
+	}
+	if method != "ldap" {
+		t.Errorf("MethodOptions() method = %q, want %q", method, "ldap")
+	}
+	want := map[string]string{
+		"ldapserver": "ldap.example.com",
+		"ldapport":   "389",
+		"ldapprefix": "cn=",
+		"ldapsuffix": ", dc=example, dc=com",
+	}
+	if !reflect.DeepEqual(options, want) {
+		t.Errorf("MethodOptions() options = %q, want %q", options, want)
+	}
+
+	row, err = conf.LookupFirst(hba.ConnType, "local")
+	if err != nil {
+		t.Fatalf("LookupFirst() errored with '%s', wanted no error", err)
+	}
+	method, options, err = conf.MethodOptions(row)
+	if err != nil || method != "peer" || len(options) != 0 {
+		t.Errorf("MethodOptions() = %q, %q, %v for a local rule, want %q, no options", method, options, err, "peer")
+	}
+}